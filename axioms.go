@@ -0,0 +1,741 @@
+package godl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClassExpression : a named class or a class constructor
+type ClassExpression interface {
+	String() string
+	classExpression()
+}
+
+// ObjectPropertyExpression : a named object property or its inverse
+type ObjectPropertyExpression interface {
+	String() string
+	objectPropertyExpression()
+}
+
+// Axiom : one of the axiom types below
+type Axiom interface {
+	axiom()
+}
+
+// entityString writes an IRI the way functional syntax expects it
+func entityString(name string) string {
+	if strings.Contains(name, ":") && !strings.HasPrefix(name, "_:") {
+		return "<" + name + ">"
+	}
+
+	return name
+}
+
+func listString(name string, args ...fmt.Stringer) string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = arg.String()
+	}
+
+	return name + "(" + strings.Join(strs, " ") + ")"
+}
+
+// Class expressions
+
+// Class : a named class
+type Class struct {
+	Name string
+}
+
+// ObjectIntersectionOf : C1 ⊓ ... ⊓ Cn
+type ObjectIntersectionOf struct {
+	Classes []ClassExpression
+}
+
+// ObjectUnionOf : C1 ⊔ ... ⊔ Cn
+type ObjectUnionOf struct {
+	Classes []ClassExpression
+}
+
+// ObjectComplementOf : ¬C
+type ObjectComplementOf struct {
+	Class ClassExpression
+}
+
+// ObjectSomeValuesFrom : ∃R.C
+type ObjectSomeValuesFrom struct {
+	Property ObjectPropertyExpression
+	Filler   ClassExpression
+}
+
+func (Class) classExpression()                {}
+func (ObjectIntersectionOf) classExpression() {}
+func (ObjectUnionOf) classExpression()        {}
+func (ObjectComplementOf) classExpression()   {}
+func (ObjectSomeValuesFrom) classExpression() {}
+
+func (c Class) String() string { return entityString(c.Name) }
+
+func (c ObjectIntersectionOf) String() string {
+	return listString("ObjectIntersectionOf", classStringers(c.Classes)...)
+}
+
+func (c ObjectUnionOf) String() string {
+	return listString("ObjectUnionOf", classStringers(c.Classes)...)
+}
+
+func (c ObjectComplementOf) String() string {
+	return listString("ObjectComplementOf", c.Class)
+}
+
+func (c ObjectSomeValuesFrom) String() string {
+	return listString("ObjectSomeValuesFrom", c.Property, c.Filler)
+}
+
+func classStringers(classes []ClassExpression) []fmt.Stringer {
+	res := make([]fmt.Stringer, len(classes))
+	for i, c := range classes {
+		res[i] = c
+	}
+
+	return res
+}
+
+// Object property expressions
+
+// ObjectProperty : a named object property
+type ObjectProperty struct {
+	Name string
+}
+
+// ObjectInverseOf : R⁻
+type ObjectInverseOf struct {
+	Property ObjectProperty
+}
+
+func (ObjectProperty) objectPropertyExpression()  {}
+func (ObjectInverseOf) objectPropertyExpression() {}
+
+func (p ObjectProperty) String() string { return entityString(p.Name) }
+
+func (p ObjectInverseOf) String() string { return listString("ObjectInverseOf", p.Property) }
+
+// DataProperty : a named data property
+type DataProperty struct {
+	Name string
+}
+
+func (p DataProperty) String() string { return entityString(p.Name) }
+
+// Axioms
+
+// Declaration : Declaration(Kind(Name)), Kind being Class, ObjectProperty,
+// DataProperty, AnnotationProperty, NamedIndividual or Datatype
+type Declaration struct {
+	Kind string
+	Name string
+}
+
+// SubClassOf : Sub ⊑ Super
+type SubClassOf struct {
+	Sub   ClassExpression
+	Super ClassExpression
+}
+
+// EquivalentClasses : C1 ≡ ... ≡ Cn
+type EquivalentClasses struct {
+	Classes []ClassExpression
+}
+
+// DisjointClasses : the classes are pairwise disjoint
+type DisjointClasses struct {
+	Classes []ClassExpression
+}
+
+// DisjointUnion : Class is the union of the pairwise disjoint Classes
+type DisjointUnion struct {
+	Class   Class
+	Classes []ClassExpression
+}
+
+// SubObjectPropertyOf : Sub ⊑ Super
+type SubObjectPropertyOf struct {
+	Sub   ObjectPropertyExpression
+	Super ObjectPropertyExpression
+}
+
+// EquivalentObjectProperties : R1 ≡ ... ≡ Rn
+type EquivalentObjectProperties struct {
+	Properties []ObjectPropertyExpression
+}
+
+// DisjointObjectProperties : the properties are pairwise disjoint
+type DisjointObjectProperties struct {
+	Properties []ObjectPropertyExpression
+}
+
+// InverseObjectProperties : First ≡ Second⁻
+type InverseObjectProperties struct {
+	First  ObjectPropertyExpression
+	Second ObjectPropertyExpression
+}
+
+// ObjectPropertyDomain : ∃Property ⊑ Domain
+type ObjectPropertyDomain struct {
+	Property ObjectPropertyExpression
+	Domain   ClassExpression
+}
+
+// ObjectPropertyRange : ∃Property⁻ ⊑ Range
+type ObjectPropertyRange struct {
+	Property ObjectPropertyExpression
+	Range    ClassExpression
+}
+
+// FunctionalObjectProperty : Property is functional
+type FunctionalObjectProperty struct {
+	Property ObjectPropertyExpression
+}
+
+// InverseFunctionalObjectProperty : the inverse of Property is functional
+type InverseFunctionalObjectProperty struct {
+	Property ObjectPropertyExpression
+}
+
+// ReflexiveObjectProperty : Property is reflexive
+type ReflexiveObjectProperty struct {
+	Property ObjectPropertyExpression
+}
+
+// IrreflexiveObjectProperty : Property is irreflexive
+type IrreflexiveObjectProperty struct {
+	Property ObjectPropertyExpression
+}
+
+// SymmetricObjectProperty : Property is symmetric
+type SymmetricObjectProperty struct {
+	Property ObjectPropertyExpression
+}
+
+// AsymmetricObjectProperty : Property is asymmetric
+type AsymmetricObjectProperty struct {
+	Property ObjectPropertyExpression
+}
+
+// TransitiveObjectProperty : Property is transitive
+type TransitiveObjectProperty struct {
+	Property ObjectPropertyExpression
+}
+
+// DataPropertyDomain : ∃Property ⊑ Domain
+type DataPropertyDomain struct {
+	Property DataProperty
+	Domain   ClassExpression
+}
+
+// DataPropertyRange : the values of Property have the datatype Range
+type DataPropertyRange struct {
+	Property DataProperty
+	Range    string
+}
+
+// ClassAssertion : Individual is an instance of Class
+type ClassAssertion struct {
+	Class      ClassExpression
+	Individual string
+}
+
+// ObjectPropertyAssertion : Property(Subject, Object)
+type ObjectPropertyAssertion struct {
+	Property ObjectPropertyExpression
+	Subject  string
+	Object   string
+}
+
+// DataPropertyAssertion : Property(Subject, Value)
+type DataPropertyAssertion struct {
+	Property DataProperty
+	Subject  string
+	Value    Literal
+}
+
+func (Declaration) axiom()                     {}
+func (SubClassOf) axiom()                      {}
+func (EquivalentClasses) axiom()               {}
+func (DisjointClasses) axiom()                 {}
+func (DisjointUnion) axiom()                   {}
+func (SubObjectPropertyOf) axiom()             {}
+func (EquivalentObjectProperties) axiom()      {}
+func (DisjointObjectProperties) axiom()        {}
+func (InverseObjectProperties) axiom()         {}
+func (ObjectPropertyDomain) axiom()            {}
+func (ObjectPropertyRange) axiom()             {}
+func (FunctionalObjectProperty) axiom()        {}
+func (InverseFunctionalObjectProperty) axiom() {}
+func (ReflexiveObjectProperty) axiom()         {}
+func (IrreflexiveObjectProperty) axiom()       {}
+func (SymmetricObjectProperty) axiom()         {}
+func (AsymmetricObjectProperty) axiom()        {}
+func (TransitiveObjectProperty) axiom()        {}
+func (ClassAssertion) axiom()                  {}
+func (ObjectPropertyAssertion) axiom()         {}
+func (DataPropertyDomain) axiom()              {}
+func (DataPropertyRange) axiom()               {}
+func (DataPropertyAssertion) axiom()           {}
+
+// Errors
+
+// AxiomError : a predicate that is not a well formed axiom
+type AxiomError struct {
+	Span Span
+	Msg  string
+}
+
+func (e *AxiomError) Error() string {
+	return e.Span.String() + ": " + e.Msg
+}
+
+// UnsupportedError : a well formed construct that has no typed counterpart
+type UnsupportedError struct {
+	Span Span
+	Name string
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.Span.String() + ": '" + e.Name + "' not supported"
+}
+
+// Conversion
+
+// converter turns predicates into typed axioms, expanding names with ns
+type converter struct {
+	ns *Namespaces
+}
+
+// arguments returns the arguments of p without the axiom annotations,
+// checking that there are at least min of them, and at most max if max >= 0
+func (c *converter) arguments(p *DLPredicate, min int, max int) ([]DLPredicate, error) {
+	args := p.Arguments
+	for len(args) > 0 && args[0].Name == "Annotation" {
+		args = args[1:]
+	}
+
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		var expected string
+		switch {
+		case min == max:
+			expected = fmt.Sprint(min)
+		case max < 0:
+			expected = fmt.Sprint("at least ", min)
+		default:
+			expected = fmt.Sprint(min, " to ", max)
+		}
+
+		return args, &AxiomError{Span: p.Span, Msg: fmt.Sprintf("'%s' expects %s arguments, got %d", p.Name, expected, len(args))}
+	}
+
+	return args, nil
+}
+
+// name returns the expanded name of the entity p
+func (c *converter) name(p *DLPredicate) (string, error) {
+	if len(p.Arguments) > 0 || p.Name == "" || p.Name[0] == '"' {
+		return "", &AxiomError{Span: p.Span, Msg: fmt.Sprintf("entity expected, got '%s'", p.Name)}
+	}
+
+	if c.ns == nil {
+		return p.Name, nil
+	}
+
+	iri, err := c.ns.Expand(p.Name)
+	if err != nil {
+		return iri, &AxiomError{Span: p.Span, Msg: err.Error()}
+	}
+
+	return iri, nil
+}
+
+// literal returns the value of the literal p
+func (c *converter) literal(p *DLPredicate) (Literal, error) {
+	if len(p.Arguments) > 0 || p.Name == "" || p.Name[0] != '"' {
+		return Literal{}, &AxiomError{Span: p.Span, Msg: fmt.Sprintf("literal expected, got '%s'", p.Name)}
+	}
+
+	literal, err := ParseLiteral(p.Name, c.ns)
+	if err != nil {
+		return literal, &AxiomError{Span: p.Span, Msg: err.Error()}
+	}
+
+	return literal, nil
+}
+
+// dataProperty returns the data property p
+func (c *converter) dataProperty(p *DLPredicate) (DataProperty, error) {
+	if len(p.Arguments) > 0 {
+		return DataProperty{}, &AxiomError{Span: p.Span, Msg: fmt.Sprintf("data property expected, got '%s'", p.Name)}
+	}
+
+	name, err := c.name(p)
+	return DataProperty{Name: name}, err
+}
+
+func (c *converter) class(p *DLPredicate) (ClassExpression, error) {
+	if len(p.Arguments) == 0 {
+		name, err := c.name(p)
+		return Class{Name: name}, err
+	}
+
+	switch p.Name {
+	case "ObjectIntersectionOf", "ObjectUnionOf":
+		args, err := c.arguments(p, 2, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		classes, err := c.classes(args)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Name == "ObjectUnionOf" {
+			return ObjectUnionOf{Classes: classes}, nil
+		}
+		return ObjectIntersectionOf{Classes: classes}, nil
+
+	case "ObjectComplementOf":
+		args, err := c.arguments(p, 1, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		class, err := c.class(&args[0])
+		return ObjectComplementOf{Class: class}, err
+
+	case "ObjectSomeValuesFrom":
+		args, err := c.arguments(p, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		property, err := c.objectProperty(&args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		filler, err := c.class(&args[1])
+		return ObjectSomeValuesFrom{Property: property, Filler: filler}, err
+	}
+
+	return nil, &UnsupportedError{Span: p.Span, Name: p.Name}
+}
+
+func (c *converter) classes(args []DLPredicate) ([]ClassExpression, error) {
+	classes := make([]ClassExpression, len(args))
+
+	for i := range args {
+		class, err := c.class(&args[i])
+		if err != nil {
+			return nil, err
+		}
+		classes[i] = class
+	}
+
+	return classes, nil
+}
+
+func (c *converter) objectProperty(p *DLPredicate) (ObjectPropertyExpression, error) {
+	if len(p.Arguments) == 0 {
+		name, err := c.name(p)
+		return ObjectProperty{Name: name}, err
+	}
+
+	if p.Name != "ObjectInverseOf" {
+		return nil, &UnsupportedError{Span: p.Span, Name: p.Name}
+	}
+
+	args, err := c.arguments(p, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args[0].Arguments) > 0 {
+		return nil, &AxiomError{Span: args[0].Span, Msg: "object property expected in 'ObjectInverseOf'"}
+	}
+
+	name, err := c.name(&args[0])
+	return ObjectInverseOf{Property: ObjectProperty{Name: name}}, err
+}
+
+func (c *converter) objectProperties(args []DLPredicate) ([]ObjectPropertyExpression, error) {
+	properties := make([]ObjectPropertyExpression, len(args))
+
+	for i := range args {
+		property, err := c.objectProperty(&args[i])
+		if err != nil {
+			return nil, err
+		}
+		properties[i] = property
+	}
+
+	return properties, nil
+}
+
+// characteristic converts the axioms holding a single object property
+func (c *converter) characteristic(p *DLPredicate) (Axiom, error) {
+	args, err := c.arguments(p, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	property, err := c.objectProperty(&args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.Name {
+	case "FunctionalObjectProperty":
+		return FunctionalObjectProperty{Property: property}, nil
+	case "InverseFunctionalObjectProperty":
+		return InverseFunctionalObjectProperty{Property: property}, nil
+	case "ReflexiveObjectProperty":
+		return ReflexiveObjectProperty{Property: property}, nil
+	case "IrreflexiveObjectProperty":
+		return IrreflexiveObjectProperty{Property: property}, nil
+	case "SymmetricObjectProperty":
+		return SymmetricObjectProperty{Property: property}, nil
+	case "AsymmetricObjectProperty":
+		return AsymmetricObjectProperty{Property: property}, nil
+	}
+
+	return TransitiveObjectProperty{Property: property}, nil
+}
+
+func (c *converter) axiom(p *DLPredicate) (Axiom, error) {
+	switch p.Name {
+	case "Declaration":
+		args, err := c.arguments(p, 1, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		entity := &args[0]
+		switch entity.Name {
+		case "Class", "ObjectProperty", "DataProperty", "AnnotationProperty", "NamedIndividual", "Datatype":
+		default:
+			return nil, &UnsupportedError{Span: entity.Span, Name: entity.Name}
+		}
+
+		if len(entity.Arguments) != 1 {
+			return nil, &AxiomError{Span: entity.Span, Msg: fmt.Sprintf("'%s' expects 1 argument, got %d", entity.Name, len(entity.Arguments))}
+		}
+
+		name, err := c.name(&entity.Arguments[0])
+		return Declaration{Kind: entity.Name, Name: name}, err
+
+	case "SubClassOf":
+		args, err := c.arguments(p, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		classes, err := c.classes(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return SubClassOf{Sub: classes[0], Super: classes[1]}, nil
+
+	case "EquivalentClasses", "DisjointClasses":
+		args, err := c.arguments(p, 2, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		classes, err := c.classes(args)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Name == "DisjointClasses" {
+			return DisjointClasses{Classes: classes}, nil
+		}
+		return EquivalentClasses{Classes: classes}, nil
+
+	case "DisjointUnion":
+		args, err := c.arguments(p, 3, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(args[0].Arguments) > 0 {
+			return nil, &AxiomError{Span: args[0].Span, Msg: "class expected in 'DisjointUnion'"}
+		}
+
+		name, err := c.name(&args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		classes, err := c.classes(args[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		return DisjointUnion{Class: Class{Name: name}, Classes: classes}, nil
+
+	case "SubObjectPropertyOf":
+		args, err := c.arguments(p, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		properties, err := c.objectProperties(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return SubObjectPropertyOf{Sub: properties[0], Super: properties[1]}, nil
+
+	case "EquivalentObjectProperties", "DisjointObjectProperties":
+		args, err := c.arguments(p, 2, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		properties, err := c.objectProperties(args)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Name == "DisjointObjectProperties" {
+			return DisjointObjectProperties{Properties: properties}, nil
+		}
+		return EquivalentObjectProperties{Properties: properties}, nil
+
+	case "InverseObjectProperties":
+		args, err := c.arguments(p, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		properties, err := c.objectProperties(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return InverseObjectProperties{First: properties[0], Second: properties[1]}, nil
+
+	case "ObjectPropertyDomain", "ObjectPropertyRange":
+		args, err := c.arguments(p, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		property, err := c.objectProperty(&args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		class, err := c.class(&args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Name == "ObjectPropertyRange" {
+			return ObjectPropertyRange{Property: property, Range: class}, nil
+		}
+		return ObjectPropertyDomain{Property: property, Domain: class}, nil
+
+	case "FunctionalObjectProperty", "InverseFunctionalObjectProperty",
+		"ReflexiveObjectProperty", "IrreflexiveObjectProperty",
+		"SymmetricObjectProperty", "AsymmetricObjectProperty", "TransitiveObjectProperty":
+		return c.characteristic(p)
+
+	case "ClassAssertion":
+		args, err := c.arguments(p, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		class, err := c.class(&args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		individual, err := c.name(&args[1])
+		return ClassAssertion{Class: class, Individual: individual}, err
+
+	case "ObjectPropertyAssertion":
+		args, err := c.arguments(p, 3, 3)
+		if err != nil {
+			return nil, err
+		}
+
+		property, err := c.objectProperty(&args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		subject, err := c.name(&args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		object, err := c.name(&args[2])
+		return ObjectPropertyAssertion{Property: property, Subject: subject, Object: object}, err
+
+	case "DataPropertyDomain", "DataPropertyRange":
+		args, err := c.arguments(p, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		property, err := c.dataProperty(&args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Name == "DataPropertyRange" {
+			if len(args[1].Arguments) > 0 {
+				return nil, &UnsupportedError{Span: args[1].Span, Name: args[1].Name}
+			}
+
+			datatype, err := c.name(&args[1])
+			return DataPropertyRange{Property: property, Range: datatype}, err
+		}
+
+		class, err := c.class(&args[1])
+		return DataPropertyDomain{Property: property, Domain: class}, err
+
+	case "DataPropertyAssertion":
+		args, err := c.arguments(p, 3, 3)
+		if err != nil {
+			return nil, err
+		}
+
+		property, err := c.dataProperty(&args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		subject, err := c.name(&args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := c.literal(&args[2])
+		return DataPropertyAssertion{Property: property, Subject: subject, Value: value}, err
+	}
+
+	return nil, &UnsupportedError{Span: p.Span, Name: p.Name}
+}
+
+// NewAxiom converts the predicate p into a typed axiom, checking the number
+// and the kind of its arguments. Names are expanded with ns when ns is not
+// nil. Constructs without typed counterpart give an *UnsupportedError, ill
+// formed axioms an *AxiomError.
+func NewAxiom(p *DLPredicate, ns *Namespaces) (Axiom, error) {
+	c := converter{ns: ns}
+
+	return c.axiom(p)
+}
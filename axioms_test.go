@@ -0,0 +1,97 @@
+package godl
+
+import (
+	"testing"
+)
+
+func TestNewAxiom(t *testing.T) {
+	s := `Prefix(:=<http://example.org/music#>)
+Ontology(
+   SubClassOf(Annotation(rdfs:comment "painters") :painter :artist)
+   DisjointClasses(:martian :venusian :jupiterian)
+   ObjectPropertyRange(ObjectInverseOf(:hasArtist) :artist)
+   SubClassOf(:artist ObjectSomeValuesFrom(:hasComposed owl:Thing))
+   ClassAssertion(:artist :Ravel)
+)`
+
+	result, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns, _ := result.Namespaces()
+	ontology := result.FindOntology()
+	axioms := make([]Axiom, len(ontology.Arguments))
+
+	for i := range ontology.Arguments {
+		if axioms[i], err = NewAxiom(&ontology.Arguments[i], ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const music = "http://example.org/music#"
+
+	if a, ok := axioms[0].(SubClassOf); !ok || a.Sub != (Class{Name: music + "painter"}) || a.Super != (Class{Name: music + "artist"}) {
+		t.Error("bad SubClassOf:", axioms[0])
+	}
+
+	if a, ok := axioms[1].(DisjointClasses); !ok || len(a.Classes) != 3 {
+		t.Error("bad DisjointClasses:", axioms[1])
+	}
+
+	if a, ok := axioms[2].(ObjectPropertyRange); !ok || a.Property != (ObjectInverseOf{Property: ObjectProperty{Name: music + "hasArtist"}}) {
+		t.Error("bad ObjectPropertyRange:", axioms[2])
+	}
+
+	a, ok := axioms[3].(SubClassOf)
+	if !ok {
+		t.Fatal("bad SubClassOf:", axioms[3])
+	}
+	if some, ok := a.Super.(ObjectSomeValuesFrom); !ok || some.Filler != (Class{Name: OWLNamespace + "Thing"}) {
+		t.Error("bad ObjectSomeValuesFrom:", a.Super)
+	}
+	if a.Super.String() != "ObjectSomeValuesFrom(<"+music+"hasComposed> <"+OWLNamespace+"Thing>)" {
+		t.Error("bad string:", a.Super.String())
+	}
+
+	if a, ok := axioms[4].(ClassAssertion); !ok || a.Individual != music+"Ravel" {
+		t.Error("bad ClassAssertion:", axioms[4])
+	}
+}
+
+func TestNewAxiomErrors(t *testing.T) {
+	tests := []struct {
+		input       string
+		unsupported bool
+	}{
+		{"SubClassOf(a)", false},
+		{"SubClassOf(a b c)", false},
+		{"ClassAssertion(a \"b\")", false},
+		{"ObjectPropertyAssertion(ObjectInverseOf(ObjectInverseOf(r)) a b)", false},
+		{"SubClassOf(a ObjectAllValuesFrom(r b))", true},
+		{"SubObjectPropertyOf(ObjectPropertyChain(r s) t)", true},
+		{"HasKey(a () (r))", true},
+	}
+
+	for _, test := range tests {
+		result, err := Parse(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewAxiom(&result.Arguments[0], nil)
+
+		switch err.(type) {
+		case *UnsupportedError:
+			if !test.unsupported {
+				t.Errorf("%s: unexpected error %s", test.input, err)
+			}
+		case *AxiomError:
+			if test.unsupported {
+				t.Errorf("%s: unexpected error %s", test.input, err)
+			}
+		default:
+			t.Errorf("%s: expected an error, got %v", test.input, err)
+		}
+	}
+}
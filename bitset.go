@@ -0,0 +1,75 @@
+package godl
+
+import (
+	"math/bits"
+)
+
+// bitset : a fixed size set of small integers, packed 64 per word
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) get(i int) bool {
+	return b[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << uint(i%64)
+}
+
+func (b bitset) clear(i int) {
+	b[i/64] &^= 1 << uint(i%64)
+}
+
+// or adds the elements of c to b
+func (b bitset) or(c bitset) {
+	for i := range c {
+		b[i] |= c[i]
+	}
+}
+
+// andNot removes the elements of c from b
+func (b bitset) andNot(c bitset) {
+	for i := range c {
+		b[i] &^= c[i]
+	}
+}
+
+// intersects tells if b and c have an element in common
+func (b bitset) intersects(c bitset) bool {
+	for i := range c {
+		if b[i]&c[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b bitset) count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+
+	return n
+}
+
+func (b bitset) copy() bitset {
+	c := make(bitset, len(b))
+	copy(c, b)
+
+	return c
+}
+
+// each calls f on the elements of b, in increasing order
+func (b bitset) each(f func(int)) {
+	for i, w := range b {
+		for w != 0 {
+			f(i*64 + bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+}
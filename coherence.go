@@ -0,0 +1,66 @@
+package godl
+
+import (
+	"fmt"
+)
+
+// Unsatisfiability : a class subsumed by ⊥, being below Left and Right,
+// asserted disjoint
+type Unsatisfiability struct {
+	Class string
+	Left  string
+	Right string
+}
+
+func (u Unsatisfiability) String() string {
+	return fmt.Sprintf("'%s' is unsatisfiable: below '%s' and '%s', which are disjoint", u.Class, u.Left, u.Right)
+}
+
+// CoherenceReport : the unsatisfiable classes found by ComputeClosure, in
+// the order of their indexes
+type CoherenceReport struct {
+	Unsatisfiable []Unsatisfiability
+}
+
+// Coherent tells if the relation has no unsatisfiable class
+func (c CoherenceReport) Coherent() bool {
+	return len(c.Unsatisfiable) == 0
+}
+
+// Unsatisfiable tells if the element i is below two disjoint elements, so
+// subsumed by ⊥
+func (r *Relation) Unsatisfiable(i int) bool {
+	return i < r.Size && r.positive[i].intersects(r.negative[i])
+}
+
+// coherenceReport finds the unsatisfiable elements of the closure, with a
+// pair of their subsumers set disjoint in asserted
+func (r *Relation) coherenceReport(asserted []bitset) CoherenceReport {
+	report := CoherenceReport{Unsatisfiable: make([]Unsatisfiability, 0)}
+
+	for k := 0; k < r.Size; k++ {
+		if !r.Unsatisfiable(k) {
+			continue
+		}
+
+		i, j := -1, -1
+		r.positive[k].each(func(a int) {
+			if i < 0 && asserted[a].intersects(r.positive[k]) {
+				i = a
+				asserted[a].each(func(b int) {
+					if j < 0 && r.positive[k].get(b) {
+						j = b
+					}
+				})
+			}
+		})
+
+		report.Unsatisfiable = append(report.Unsatisfiable, Unsatisfiability{Class: r.Elements[k], Left: r.Elements[i], Right: r.Elements[j]})
+
+		if r.Debug {
+			r.printConflict(k)
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,58 @@
+package godl
+
+import (
+	"testing"
+)
+
+func TestCoherenceReport(t *testing.T) {
+	r := NewRelation(0)
+	r.AutoRegister = true
+
+	r.SetSubClassOf("painter", "artist")
+	r.SetSubClassOf("artist", "human")
+	r.SetDisjointClasses("human", "piece")
+	r.SetSubClassOf("sculpture", "piece")
+
+	if report := r.ComputeAll(); !report.Coherent() {
+		t.Fatal("unexpected unsatisfiable classes:", report.Unsatisfiable)
+	}
+
+	r = NewRelation(0)
+	r.AutoRegister = true
+
+	r.SetSubClassOf("painter", "artist")
+	r.SetSubClassOf("artist", "human")
+	r.SetDisjointClasses("human", "piece")
+	r.SetSubClassOf("sculpture", "piece")
+	r.SetSubClassOf("cubist", "painter")
+	if ok, _ := r.SetSubClassOf("painter", "sculpture"); !ok {
+		t.Error("painter ⊑ sculpture reported as a direct conflict")
+	}
+	if ok, _ := r.SetDisjointClasses("artist", "human"); ok {
+		t.Error("artist ⊑ human and disjoint not reported")
+	}
+
+	report := r.ComputeAll()
+
+	expected := []Unsatisfiability{
+		{Class: "painter", Left: "artist", Right: "human"},
+		{Class: "artist", Left: "artist", Right: "human"},
+		{Class: "cubist", Left: "artist", Right: "human"},
+	}
+
+	if len(report.Unsatisfiable) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, report.Unsatisfiable)
+	}
+
+	for i, u := range report.Unsatisfiable {
+		if u != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], u)
+		}
+	}
+
+	for _, name := range []string{"human", "piece", "sculpture"} {
+		if r.Unsatisfiable(r.IndexOf[name]) {
+			t.Error(name, "is satisfiable")
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package godl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// The binary representation of a Relation: the magic bytes, the format
+// version as a uvarint, the payload, and the CRC-32 (IEEE) of all that, 4
+// bytes little endian. In the payload, every integer is a uvarint, strings
+// are written as their length then their bytes, and the rows of the
+// matrices as their number of elements then the gaps between them.
+const (
+	binaryMagic   = "GoDL\x00REL"
+	BinaryVersion = 1
+)
+
+// ErrChecksum : a binary relation whose checksum does not match
+var ErrChecksum = errors.New("relation: bad checksum")
+
+// binaryWriter writes the payload
+type binaryWriter struct {
+	buffer bytes.Buffer
+	tmp    [binary.MaxVarintLen64]byte
+}
+
+func (w *binaryWriter) uint(v int) {
+	n := binary.PutUvarint(w.tmp[:], uint64(v))
+	w.buffer.Write(w.tmp[:n])
+}
+
+func (w *binaryWriter) string(s string) {
+	w.uint(len(s))
+	w.buffer.WriteString(s)
+}
+
+// row writes the elements of b below size
+func (w *binaryWriter) row(b bitset, size int) {
+	elements := make([]int, 0)
+	if b != nil {
+		b.each(func(j int) {
+			if j < size {
+				elements = append(elements, j)
+			}
+		})
+	}
+
+	w.uint(len(elements))
+	previous := 0
+	for _, j := range elements {
+		w.uint(j - previous)
+		previous = j
+	}
+}
+
+// binaryReader reads the payload, keeping the first error
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binaryReader) uint() int {
+	if r.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 || v > 1<<62 {
+		r.err = errors.New("relation: truncated or invalid data")
+		return 0
+	}
+	r.data = r.data[n:]
+
+	return int(v)
+}
+
+func (r *binaryReader) string() string {
+	n := r.uint()
+	if r.err != nil {
+		return ""
+	}
+	if n > len(r.data) {
+		r.err = errors.New("relation: truncated string")
+		return ""
+	}
+
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+
+	return s
+}
+
+// index reads an index below size
+func (r *binaryReader) index(size int) int {
+	i := r.uint()
+	if r.err == nil && i >= size {
+		r.err = fmt.Errorf("relation: index %d out of range", i)
+		return 0
+	}
+
+	return i
+}
+
+// count reads a number of items, at most max
+func (r *binaryReader) count(max int) int {
+	n := r.uint()
+	if r.err == nil && n > max {
+		r.err = fmt.Errorf("relation: count %d out of range", n)
+		return 0
+	}
+
+	return n
+}
+
+func (r *binaryReader) row(b bitset, size int) {
+	n := r.count(size)
+	j := 0
+	for k := 0; k < n && r.err == nil; k++ {
+		j += r.uint()
+		if j >= size {
+			r.err = fmt.Errorf("relation: index %d out of range", j)
+			return
+		}
+		b.set(j)
+	}
+}
+
+// MarshalBinary writes the relation in the versioned binary format, its
+// matrices as sparse rows. Capacity and Debug are not kept.
+func (r *Relation) MarshalBinary() ([]byte, error) {
+	var w binaryWriter
+
+	w.buffer.WriteString(binaryMagic)
+	w.uint(BinaryVersion)
+
+	w.uint(r.Size)
+	for _, e := range r.Elements {
+		w.string(e)
+	}
+
+	w.uint(len(r.Weights))
+	for _, weight := range r.Weights {
+		w.uint(weight)
+	}
+
+	w.uint(len(r.EquivalentClasses))
+	for _, eqClass := range r.EquivalentClasses {
+		w.uint(len(eqClass))
+		for _, index := range eqClass {
+			w.uint(index)
+		}
+	}
+
+	for _, rows := range [][]bitset{r.positive, r.negative, r.compactPositive, r.compactNegative} {
+		for i := 0; i < r.Size; i++ {
+			if i < len(rows) {
+				w.row(rows[i], r.Size)
+			} else {
+				w.row(nil, r.Size)
+			}
+		}
+	}
+
+	w.uint(len(r.assertions))
+	for _, a := range r.assertions {
+		w.uint(r.IndexOf[a.Left])
+		w.uint(r.IndexOf[a.Right])
+		if a.Disjoint {
+			w.uint(1)
+		} else {
+			w.uint(0)
+		}
+		w.string(a.Axiom)
+	}
+
+	var crc [4]byte
+	binary.LittleEndian.PutUint32(crc[:], crc32.ChecksumIEEE(w.buffer.Bytes()))
+	w.buffer.Write(crc[:])
+
+	return w.buffer.Bytes(), nil
+}
+
+// UnmarshalBinary reads a relation written by MarshalBinary. It fails with
+// ErrChecksum on corrupted data.
+func (r *Relation) UnmarshalBinary(data []byte) error {
+	if !IsBinaryRelation(data) || len(data) < len(binaryMagic)+4 {
+		return errors.New("relation: not a binary relation")
+	}
+
+	payload, crc := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(crc) {
+		return ErrChecksum
+	}
+
+	in := binaryReader{data: payload[len(binaryMagic):]}
+	if version := in.uint(); in.err == nil && version != BinaryVersion {
+		return fmt.Errorf("relation: unsupported format version %d", version)
+	}
+
+	// an element takes a byte at least
+	size := in.count(len(in.data))
+	if in.err != nil {
+		return in.err
+	}
+
+	res := NewRelation(size)
+	for i := 0; i < size && in.err == nil; i++ {
+		res.AddElement(in.string())
+	}
+	if in.err == nil && res.Size != size {
+		return errors.New("relation: duplicate elements")
+	}
+
+	if n := in.count(size); in.err == nil {
+		res.Weights = make([]int, 0, size)
+		for k := 0; k < n && in.err == nil; k++ {
+			res.Weights = append(res.Weights, in.uint())
+		}
+	}
+
+	n := in.count(size)
+	for k := 0; k < n && in.err == nil; k++ {
+		eqClass := make([]int, in.count(size))
+		for l := range eqClass {
+			eqClass[l] = in.index(size)
+		}
+		res.EquivalentClasses = append(res.EquivalentClasses, eqClass)
+	}
+
+	res.compactPositive = make([]bitset, res.Capacity)
+	res.compactNegative = make([]bitset, res.Capacity)
+	for i := 0; i < res.Capacity; i++ {
+		res.compactPositive[i] = newBitset(res.Capacity)
+		res.compactNegative[i] = newBitset(res.Capacity)
+	}
+
+	for _, rows := range [][]bitset{res.positive, res.negative, res.compactPositive, res.compactNegative} {
+		for i := 0; i < size && in.err == nil; i++ {
+			in.row(rows[i], size)
+		}
+	}
+
+	n = in.count(len(in.data))
+	for k := 0; k < n && in.err == nil; k++ {
+		i, j := in.index(size), in.index(size)
+		disjoint := in.uint() == 1
+		res.Source = in.string()
+		if in.err == nil {
+			res.assert(i, j, disjoint)
+		}
+	}
+	res.Source = ""
+
+	if in.err != nil {
+		return in.err
+	}
+	if len(in.data) != 0 {
+		return errors.New("relation: trailing data")
+	}
+
+	*r = *res
+
+	return nil
+}
+
+// IsBinaryRelation tells if data starts like the binary format
+func IsBinaryRelation(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(binaryMagic))
+}
+
+// DecodeRelation reads a relation stored in the binary format or, for the
+// databases of older versions, in JSON
+func DecodeRelation(data []byte, r *Relation) error {
+	if IsBinaryRelation(data) {
+		return r.UnmarshalBinary(data)
+	}
+
+	return json.Unmarshal(data, r)
+}
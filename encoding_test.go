@@ -0,0 +1,125 @@
+package godl
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// sameRelation reports the first difference between r1 and r2
+func sameRelation(r1 *Relation, r2 *Relation) error {
+	if !reflect.DeepEqual(r1.Elements, r2.Elements) {
+		return fmt.Errorf("elements: %v, %v", r1.Elements, r2.Elements)
+	}
+
+	if !reflect.DeepEqual(r1.EquivalentClasses, r2.EquivalentClasses) {
+		return fmt.Errorf("equivalent classes: %v, %v", r1.EquivalentClasses, r2.EquivalentClasses)
+	}
+
+	if len(r1.Weights) != 0 || len(r2.Weights) != 0 {
+		if !reflect.DeepEqual(r1.Weights, r2.Weights) {
+			return fmt.Errorf("weights: %v, %v", r1.Weights, r2.Weights)
+		}
+	}
+
+	if !reflect.DeepEqual(r1.Assertions(), r2.Assertions()) {
+		return fmt.Errorf("assertions: %v, %v", r1.Assertions(), r2.Assertions())
+	}
+
+	for i := 0; i < r1.Size; i++ {
+		for j := 0; j < r1.Size; j++ {
+			if r1.Incidence(i, j) != r2.Incidence(i, j) {
+				return fmt.Errorf("incidence(%d, %d): %d, %d", i, j, r1.Incidence(i, j), r2.Incidence(i, j))
+			}
+			if r1.CompactIncidence(i, j) != r2.CompactIncidence(i, j) {
+				return fmt.Errorf("compact incidence(%d, %d): %d, %d", i, j, r1.CompactIncidence(i, j), r2.CompactIncidence(i, j))
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	relations := []*Relation{taxonomyRelation(), NewRelation(0)}
+
+	random := rand.New(rand.NewSource(24))
+	for round := 0; round < 10; round++ {
+		n := 1 + random.Intn(200)
+		r := NewRelation(n)
+		for i := 0; i < n; i++ {
+			r.AddElement(fmt.Sprint("c", i))
+		}
+		for e := 0; e < 2*n; e++ {
+			r.SetSubClassOfIndex(random.Intn(n), random.Intn(n))
+		}
+		for e := 0; e < n/4; e++ {
+			r.SetDisjointClassesIndex(random.Intn(n), random.Intn(n))
+		}
+		r.ComputeAll()
+		relations = append(relations, r)
+	}
+
+	for k, r := range relations {
+		data, err := r.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res Relation
+		if err := DecodeRelation(data, &res); err != nil {
+			t.Fatalf("relation %d: %v", k, err)
+		}
+
+		if err := sameRelation(r, &res); err != nil {
+			t.Errorf("relation %d: %v", k, err)
+		}
+
+		// the decoded relation can still be extended
+		res.AutoRegister = true
+		if _, err := res.SetSubClassOf("new", "other"); err != nil || res.Size != r.Size+2 {
+			t.Errorf("relation %d: cannot extend the decoded relation: %v", k, err)
+		}
+	}
+}
+
+func TestBinaryChecksum(t *testing.T) {
+	data, _ := taxonomyRelation().MarshalBinary()
+
+	for _, k := range []int{len(binaryMagic), len(data) / 2, len(data) - 1} {
+		corrupted := append([]byte(nil), data...)
+		corrupted[k] ^= 0x10
+
+		var r Relation
+		if err := r.UnmarshalBinary(corrupted); err != ErrChecksum {
+			t.Errorf("byte %d: expected %v, got %v", k, ErrChecksum, err)
+		}
+	}
+
+	var r Relation
+	if err := r.UnmarshalBinary(data[:len(data)-5]); err == nil {
+		t.Error("truncated data decoded")
+	}
+}
+
+func TestDecodeJSONRelation(t *testing.T) {
+	r := taxonomyRelation()
+	data, err := r.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if IsBinaryRelation(data) {
+		t.Fatal("JSON taken for the binary format")
+	}
+
+	var res Relation
+	if err := DecodeRelation(data, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sameRelation(r, &res); err != nil {
+		t.Error(err)
+	}
+}
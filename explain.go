@@ -0,0 +1,190 @@
+package godl
+
+import (
+	"errors"
+)
+
+// Assertion : an entry of the relation as it was set, Left ⊑ Right or Left
+// and Right disjoint, with the axiom it comes from
+type Assertion struct {
+	Left     string
+	Right    string
+	Disjoint bool
+	Axiom    string `json:",omitempty"`
+}
+
+// String returns the assertion in DL notation, followed by its axiom
+func (a Assertion) String() string {
+	s := a.Left + " ⊑ " + a.Right
+	if a.Disjoint {
+		s = a.Left + " ⊑ ¬" + a.Right
+	}
+
+	if a.Axiom != "" {
+		s += "\t" + a.Axiom
+	}
+
+	return s
+}
+
+// NotEntailedError : a pair of elements neither subsumed nor disjoint
+type NotEntailedError struct {
+	Left  string
+	Right string
+}
+
+func (e *NotEntailedError) Error() string {
+	return "'" + e.Left + "' is neither subsumed by nor disjoint from '" + e.Right + "'"
+}
+
+// assert records the entry (i, j) set by SetSubClassOf, SetDisjointClasses
+// or their incremental versions, once, with the current Source
+func (r *Relation) assert(i int, j int, disjoint bool) {
+	if r.asserted == nil {
+		r.asserted = make(map[Change]bool)
+	}
+
+	key := Change{Left: i, Right: j, Disjoint: disjoint}
+	if disjoint && i > j {
+		key.Left, key.Right = j, i
+	}
+
+	if r.asserted[key] {
+		return
+	}
+
+	r.asserted[key] = true
+	r.assertions = append(r.assertions, Assertion{Left: r.Elements[i], Right: r.Elements[j], Disjoint: disjoint, Axiom: r.Source})
+}
+
+// Assertions returns the entries of the relation as they were set, in order
+func (r *Relation) Assertions() []Assertion {
+	return r.assertions
+}
+
+// path returns the shortest chain of asserted subsumptions from i to j,
+// nil if there is none
+func (r *Relation) path(i int, j int, edges map[int][]int) []Assertion {
+	if i == j {
+		return []Assertion{}
+	}
+
+	// breadth first search, from[k] being the assertion reaching k
+	from := map[int]int{i: -1}
+	queue := []int{i}
+
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+
+		for _, a := range edges[k] {
+			l := r.IndexOf[r.assertions[a].Right]
+			if _, ok := from[l]; ok {
+				continue
+			}
+			from[l] = a
+
+			if l == j {
+				res := make([]Assertion, 0)
+				for l != i {
+					res = append([]Assertion{r.assertions[from[l]]}, res...)
+					l = r.IndexOf[r.assertions[from[l]].Left]
+				}
+				return res
+			}
+
+			queue = append(queue, l)
+		}
+	}
+
+	return nil
+}
+
+// Explain returns the assertions entailing subsumee ⊑ subsumer, or making
+// subsumee and subsumer disjoint: a chain of subsumptions in the first
+// case, and in the second the chains from each element to two elements
+// asserted disjoint, around that assertion. It fails with an
+// *UnknownElementError for unknown names and a *NotEntailedError when
+// the elements are not related.
+func (r *Relation) Explain(subsumee string, subsumer string) ([]Assertion, error) {
+	i, ok := r.IndexOf[subsumee]
+	if !ok {
+		return nil, &UnknownElementError{Name: subsumee}
+	}
+
+	j, ok := r.IndexOf[subsumer]
+	if !ok {
+		return nil, &UnknownElementError{Name: subsumer}
+	}
+
+	return r.ExplainIndex(i, j)
+}
+
+// ExplainIndex is Explain, index version
+func (r *Relation) ExplainIndex(i int, j int) ([]Assertion, error) {
+	var res []Assertion
+
+	switch r.Incidence(i, j) {
+	case 0:
+		return nil, &NotEntailedError{Left: r.Elements[i], Right: r.Elements[j]}
+	case 1:
+		res = r.explainSubClassOf(i, j)
+	case -1:
+		res = r.explainDisjoint(i, j)
+	}
+
+	if res == nil {
+		// relations read from older databases
+		return nil, errors.New("no assertion recorded for '" + r.Elements[i] + "' and '" + r.Elements[j] + "'")
+	}
+
+	return res, nil
+}
+
+// edges returns the asserted subsumptions by subsumee, and the asserted
+// disjointnesses
+func (r *Relation) edges() (map[int][]int, []int) {
+	edges := make(map[int][]int)
+	disjoint := make([]int, 0)
+
+	for a, assertion := range r.assertions {
+		if assertion.Disjoint {
+			disjoint = append(disjoint, a)
+		} else {
+			left := r.IndexOf[assertion.Left]
+			edges[left] = append(edges[left], a)
+		}
+	}
+
+	return edges, disjoint
+}
+
+// explainSubClassOf returns the shortest chain of asserted subsumptions
+// from i to j, nil if there is none
+func (r *Relation) explainSubClassOf(i int, j int) []Assertion {
+	edges, _ := r.edges()
+
+	return r.path(i, j, edges)
+}
+
+// explainDisjoint returns the shortest justification of the disjointness
+// of i and j, nil if there is none
+func (r *Relation) explainDisjoint(i int, j int) []Assertion {
+	edges, disjoint := r.edges()
+	var res []Assertion
+
+	for _, a := range disjoint {
+		k, l := r.IndexOf[r.assertions[a].Left], r.IndexOf[r.assertions[a].Right]
+
+		for _, pair := range [][2]int{{k, l}, {l, k}} {
+			left := r.path(i, pair[0], edges)
+			right := r.path(j, pair[1], edges)
+
+			if left != nil && right != nil && (res == nil || len(left)+len(right)+1 < len(res)) {
+				res = append(append(left, r.assertions[a]), right...)
+			}
+		}
+	}
+
+	return res
+}
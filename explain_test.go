@@ -0,0 +1,90 @@
+package godl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	r := NewRelation(0)
+	r.AutoRegister = true
+
+	set := func(source string, f func() (bool, error)) {
+		r.Source = source
+		if _, err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set("a1", func() (bool, error) { return r.SetSubClassOf("painter", "artist") })
+	set("a2", func() (bool, error) { return r.SetSubClassOf("artist", "human") })
+	set("a3", func() (bool, error) { return r.SetSubClassOf("artist", "fool") })
+	set("a3", func() (bool, error) { return r.SetSubClassOf("fool", "artist") })
+	set("a4", func() (bool, error) { return r.SetSubClassOf("cubist", "painter") })
+	set("a5", func() (bool, error) { return r.SetDisjointClasses("human", "piece") })
+	set("a6", func() (bool, error) { return r.SetSubClassOf("sculpture", "piece") })
+	r.Source = ""
+	r.ComputeAll()
+
+	sources := func(assertions []Assertion) string {
+		s := ""
+		for _, a := range assertions {
+			s += a.Axiom + " "
+		}
+		return s
+	}
+
+	tests := []struct {
+		left, right string
+		expected    string
+	}{
+		{"cubist", "human", "a4 a1 a2 "},
+		{"fool", "human", "a3 a2 "},
+		{"painter", "painter", ""},
+		{"cubist", "sculpture", "a4 a1 a2 a5 a6 "},
+		{"piece", "fool", "a5 a3 a2 "},
+	}
+
+	for _, test := range tests {
+		assertions, err := r.Explain(test.left, test.right)
+		if err != nil {
+			t.Errorf("%s, %s: %s", test.left, test.right, err)
+		} else if s := sources(assertions); s != test.expected {
+			t.Errorf("%s, %s: expected %s, got %s", test.left, test.right, test.expected, s)
+		}
+	}
+
+	if _, err := r.Explain("human", "painter"); err == nil {
+		t.Error("human ⊑ painter explained")
+	} else if _, ok := err.(*NotEntailedError); !ok {
+		t.Error("expected a NotEntailedError, got", err)
+	}
+
+	if _, err := r.Explain("human", "unknown"); err == nil {
+		t.Error("unknown element explained")
+	}
+
+	// the assertions survive the JSON representation
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var read Relation
+	if err := json.Unmarshal(data, &read); err != nil {
+		t.Fatal(err)
+	}
+
+	if assertions, err := read.Explain("cubist", "sculpture"); err != nil || sources(assertions) != "a4 a1 a2 a5 a6 " {
+		t.Error("bad explanation after JSON round trip:", assertions, err)
+	}
+
+	// incremental additions are recorded
+	r.Source = "a7"
+	if _, err := r.AddSubClassOf("sculpture", "statue"); err != nil {
+		t.Fatal(err)
+	}
+	if assertions, err := r.Explain("sculpture", "statue"); err != nil || sources(assertions) != "a7 " {
+		t.Error("bad explanation of an incremental addition:", assertions, err)
+	}
+}
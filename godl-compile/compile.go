@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"godl"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -25,11 +26,17 @@ var state struct {
 	fullname             string
 	db                   *sql.DB
 	stats                bool
+	axioms               string
+	dictionary           *godl.Dictionary
 	relation             godl.Relation
+	roles                godl.Relation
 	origins              []string
 	inconsistencyDegrees []float64
 	originIndexes        map[string]int
 	objectPropertyNames  []string
+	dataPropertyNames    []string
+	functionalProperties []string
+	characteristics      map[string][]string
 }
 
 func computeInconsistencyDegrees() {
@@ -47,14 +54,16 @@ func computeInconsistencyDegrees() {
 }
 
 func computeInconsistencyDegree(origins []int) {
-	query := `SELECT value FROM '%s' WHERE (origin='` + state.origins[origins[0]] + `'`
+	condition := `(origin='` + state.origins[origins[0]] + `'`
 
 	for i := 1; i < len(origins); i++ {
 		table := state.origins[origins[i]]
-		query = query + ` OR origin='` + table + `'`
+		condition = condition + ` OR origin='` + table + `'`
 	}
 
-	query += ")"
+	condition += ")"
+
+	query := `SELECT value FROM '%s' WHERE ` + condition
 
 	for _, table := range state.relation.Elements {
 		subQuery := fmt.Sprintf(query, table)
@@ -64,18 +73,92 @@ func computeInconsistencyDegree(origins []int) {
 		joinQuery := fmt.Sprintf(`SELECT MAX(weight), origin FROM (%s) AS joined, '%s' WHERE '%s'.value=joined.value GROUP BY origin`,
 			queryUnion, table, table)
 
-		rows, _ := state.db.Query(joinQuery)
-		for rows.Next() {
-			var w float64
-			var o string
+		updateInconsistencyDegrees(joinQuery)
+	}
+
+	// R(a, b) and R(a, c) with b ≠ c, R being functional
+	for _, role := range state.functionalProperties {
+		table, inverse := roleTable(role)
+		value, other := "leftValue", "rightValue"
+		if inverse {
+			value, other = other, value
+		}
+
+		conflicts := fmt.Sprintf(`SELECT DISTINCT t1.%s AS value FROM '%s' AS t1, '%s' AS t2
+			WHERE t1.%s=t2.%s AND t1.%s<>t2.%s AND t1.positive AND t2.positive AND %s AND %s`,
+			value, table, table, value, value, other, other,
+			strings.Replace(condition, "origin", "t1.origin", -1), strings.Replace(condition, "origin", "t2.origin", -1))
+		joinQuery := fmt.Sprintf(`SELECT MAX(weight), origin FROM (%s) AS joined, '%s' WHERE '%s'.%s=joined.value AND positive GROUP BY origin`,
+			conflicts, table, table, value)
 
-			if err := rows.Scan(&w, &o); err != nil {
-				log.Fatal(err)
+		updateInconsistencyDegrees(joinQuery)
+	}
+
+	// R(a, a), R being irreflexive
+	for _, role := range state.characteristics["Irreflexive"] {
+		table, _ := roleTable(role)
+		updateInconsistencyDegrees(fmt.Sprintf(`SELECT MAX(weight), origin FROM '%s' WHERE leftValue=rightValue AND positive AND %s GROUP BY origin`,
+			table, condition))
+	}
+
+	// R(a, b) and S(a, b), R and S being disjoint, S⁻ for R asymmetric
+	roles := &state.roles
+	for i := 0; i < roles.Size; i++ {
+		for j := 0; j < roles.Size; j++ {
+			if roles.Incidence(i, j) != -1 || strings.HasSuffix(roles.Elements[i], godl.InverseSuffix) {
+				continue
 			}
 
-			if index := state.originIndexes[o]; w > state.inconsistencyDegrees[index] {
-				state.inconsistencyDegrees[index] = w
+			table1, _ := roleTable(roles.Elements[i])
+			table2, inverse := roleTable(roles.Elements[j])
+			left, right := "leftValue", "rightValue"
+			if inverse {
+				left, right = right, left
 			}
+
+			conflicts := fmt.Sprintf(`SELECT DISTINCT t1.leftValue AS l, t1.rightValue AS r FROM '%s' AS t1, '%s' AS t2
+				WHERE t1.leftValue=t2.%s AND t1.rightValue=t2.%s AND t1.positive AND t2.positive AND %s AND %s`,
+				table1, table2, left, right,
+				strings.Replace(condition, "origin", "t1.origin", -1), strings.Replace(condition, "origin", "t2.origin", -1))
+
+			updateInconsistencyDegrees(fmt.Sprintf(`SELECT MAX(weight), origin FROM (%s) AS joined, '%s' WHERE leftValue=joined.l AND rightValue=joined.r AND positive GROUP BY origin`,
+				conflicts, table1))
+			updateInconsistencyDegrees(fmt.Sprintf(`SELECT MAX(weight), origin FROM (%s) AS joined, '%s' WHERE %s=joined.l AND %s=joined.r AND positive GROUP BY origin`,
+				conflicts, table2, left, right))
+		}
+	}
+}
+
+// roleTable returns the table of the element role of the role relation,
+// and whether role stands for the inverse of that table
+func roleTable(role string) (string, bool) {
+	if strings.HasSuffix(role, godl.InverseSuffix) {
+		return strings.TrimSuffix(role, godl.InverseSuffix), true
+	}
+
+	return role, false
+}
+
+// updateInconsistencyDegrees raises the degrees of the origins to the
+// weights given by query, as (weight, origin) rows
+func updateInconsistencyDegrees(query string) {
+	rows, err := state.db.Query(query)
+	if err != nil {
+		log.Println("Warning:", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w float64
+		var o string
+
+		if err := rows.Scan(&w, &o); err != nil {
+			log.Fatal(err)
+		}
+
+		if index := state.originIndexes[o]; w > state.inconsistencyDegrees[index] {
+			state.inconsistencyDegrees[index] = w
 		}
 	}
 }
@@ -90,6 +173,10 @@ func restoreConsistancy() {
 			for _, table := range state.objectPropertyNames {
 				cut(table, val, state.inconsistencyDegrees[i])
 			}
+
+			for _, table := range state.dataPropertyNames {
+				cut(table, val, state.inconsistencyDegrees[i])
+			}
 		}
 	}
 }
@@ -116,6 +203,7 @@ func parseFlags() {
 	var list bool
 	flag.BoolVar(&list, "l", false, "list available databases")
 	flag.BoolVar(&state.stats, "s", false, "print some stats")
+	flag.StringVar(&state.axioms, "a", "", "file of axioms to add to a database compiled already, populating only the tables they affect; only SubClassOf, EquivalentClasses and DisjointClasses between named classes are supported")
 
 	var version bool
 	flag.BoolVar(&version, "v", false, "version")
@@ -152,8 +240,6 @@ func parseFlags() {
 func init() {
 	usr, _ := user.Current()
 	state.dirname = usr.HomeDir + "/" + "GoDL"
-
-	parseFlags()
 }
 
 func openDB() {
@@ -175,7 +261,7 @@ func importRelation() {
 	query := `select value from __GoDL_JSON__ where name = 'TBox';`
 	row := state.db.QueryRow(query)
 
-	var raw string
+	var raw []byte
 	err := row.Scan(&raw)
 
 	if err != nil {
@@ -183,8 +269,87 @@ func importRelation() {
 		os.Exit(1)
 	}
 
+	if err = godl.DecodeRelation(raw, &state.relation); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// importFunctionalProperties reads the functional roles, absent from the
+// databases of older versions
+func importFunctionalProperties() {
+	query := `SELECT value FROM __GoDL_JSON__ WHERE name = 'functionalProperties';`
+	row := state.db.QueryRow(query)
+
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		return
+	}
+
+	reader := strings.NewReader(raw)
+	json.NewDecoder(reader).Decode(&state.functionalProperties)
+}
+
+// importCharacteristics reads the symmetric, asymmetric, reflexive and
+// irreflexive roles, absent from the databases of older versions
+func importCharacteristics() {
+	query := `SELECT value FROM __GoDL_JSON__ WHERE name = 'propertyCharacteristics';`
+	row := state.db.QueryRow(query)
+
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		return
+	}
+
 	reader := strings.NewReader(raw)
-	err = json.NewDecoder(reader).Decode(&state.relation)
+	json.NewDecoder(reader).Decode(&state.characteristics)
+}
+
+// importRoles reads the role relation, absent from the databases of older
+// versions
+func importRoles() {
+	query := `SELECT value FROM __GoDL_JSON__ WHERE name = 'RBox';`
+	row := state.db.QueryRow(query)
+
+	var raw []byte
+	if err := row.Scan(&raw); err != nil {
+		return
+	}
+
+	if err := godl.DecodeRelation(raw, &state.roles); err != nil {
+		log.Println("Warning:", err)
+	}
+}
+
+// importDictionary reads the short names of the IRIs, absent from the
+// databases of older versions
+func importDictionary() {
+	state.dictionary = godl.NewDictionary()
+
+	query := `SELECT value FROM __GoDL_JSON__ WHERE name = 'IRIs';`
+	row := state.db.QueryRow(query)
+
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		return
+	}
+
+	iris := make(map[string]string)
+	if err := json.Unmarshal([]byte(raw), &iris); err != nil {
+		log.Println("Warning:", err)
+	}
+
+	for name, iri := range iris {
+		state.dictionary.Add(name, iri)
+	}
+}
+
+// saveRelation writes the class relation back to the database
+func saveRelation() {
+	val, _ := state.relation.MarshalBinary()
+	if _, err := state.db.Exec("UPDATE __GoDL_JSON__ SET value = ? WHERE name = 'TBox';", val); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func importObjectPropertyNames() {
@@ -203,6 +368,21 @@ func importObjectPropertyNames() {
 	err = json.NewDecoder(reader).Decode(&state.objectPropertyNames)
 }
 
+// importDataPropertyNames reads the data properties, absent from the
+// databases of older versions
+func importDataPropertyNames() {
+	query := `select value from __GoDL_JSON__ where name = 'dataPropertyNames';`
+	row := state.db.QueryRow(query)
+
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		return
+	}
+
+	reader := strings.NewReader(raw)
+	json.NewDecoder(reader).Decode(&state.dataPropertyNames)
+}
+
 func importOrigins() {
 	query := `SELECT value FROM __GoDL_JSON__ WHERE name = 'origins';`
 	row := state.db.QueryRow(query)
@@ -247,7 +427,7 @@ func populate() {
 
 		// 2 : on peuple les induits (positifs et négatifs)
 		for i := 0; i < state.relation.Size; i++ {
-			positive := state.relation.CompactIncidenceMatrix[index][i]
+			positive := state.relation.CompactIncidence(index, i)
 			switch positive {
 			case 1:
 				tableDst := state.relation.Elements[i]
@@ -270,6 +450,68 @@ func populate() {
 	}
 }
 
+// populateRoles copies the assertions of each object property into the
+// tables of its super-properties, swapping the columns between a property
+// and the inverse of another one
+func populateRoles() {
+	roles := &state.roles
+
+	for i := 0; i < roles.Size; i++ {
+		src, inverse := roleTable(roles.Elements[i])
+		if inverse {
+			// R⁻ ⊑ S⁻ is R ⊑ S, R⁻ ⊑ S is R ⊑ S⁻
+			continue
+		}
+
+		for j := 0; j < roles.Size; j++ {
+			if i == j || roles.Incidence(i, j) != 1 {
+				continue
+			}
+
+			dst, inverse := roleTable(roles.Elements[j])
+			populatePropertyTable(src, dst, inverse)
+		}
+	}
+}
+
+// populateReflexiveRoles adds R(a, a) for every individual a of the ABox,
+// R being reflexive, with the weights and origins of the assertions on a.
+// The individuals are the values of the class tables, which godl-import
+// fills for the subjects and objects of the property assertions too. As a
+// then belongs to ∃R and ∃R⁻, it runs before populate.
+func populateReflexiveRoles() {
+	for _, role := range state.characteristics["Reflexive"] {
+		table, _ := roleTable(role)
+
+		for _, class := range state.relation.Elements {
+			queries := []string{
+				fmt.Sprintf(`INSERT OR IGNORE INTO '%s' SELECT value, value, 1, weight, origin FROM '%s' WHERE positive = 1;`, table, class),
+				fmt.Sprintf(`INSERT OR IGNORE INTO '%s' SELECT value, 1, weight, origin FROM '%s' WHERE positive = 1;`, table+godl.LeftSuffix, class),
+				fmt.Sprintf(`INSERT OR IGNORE INTO '%s' SELECT value, 1, weight, origin FROM '%s' WHERE positive = 1;`, table+godl.RightSuffix, class),
+			}
+
+			for _, query := range queries {
+				if _, err := state.db.Exec(query); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func populatePropertyTable(src string, dst string, inverse bool) {
+	query := `INSERT OR IGNORE INTO '%s' SELECT leftValue, rightValue, 1, weight, origin FROM '%s' WHERE positive = 1;`
+	if inverse {
+		query = `INSERT OR IGNORE INTO '%s' SELECT rightValue, leftValue, 1, weight, origin FROM '%s' WHERE positive = 1;`
+	}
+
+	query = fmt.Sprintf(query, dst, src)
+
+	if _, err := state.db.Exec(query); err != nil {
+		log.Fatal(err)
+	}
+}
+
 func copyIntoTable(src string, dst string) {
 	query := `INSERT OR IGNORE INTO '%s' SELECT value, positive, weight, origin FROM '%s';`
 	query = fmt.Sprintf(query, dst, src)
@@ -299,6 +541,157 @@ func populateTable(src string, dst string, positive bool) {
 	}
 }
 
+// addedAxiom : an axiom of the file state.axioms, and where it is
+type addedAxiom struct {
+	axiom     godl.Axiom
+	predicate godl.DLPredicate
+}
+
+// axiomClasses returns the classes of axiom, which must be a SubClassOf,
+// an EquivalentClasses or a DisjointClasses between named classes
+func axiomClasses(axiom godl.Axiom, predicate *godl.DLPredicate) ([]string, error) {
+	var ces []godl.ClassExpression
+
+	switch axiom := axiom.(type) {
+	case godl.SubClassOf:
+		ces = []godl.ClassExpression{axiom.Sub, axiom.Super}
+	case godl.EquivalentClasses:
+		ces = axiom.Classes
+	case godl.DisjointClasses:
+		ces = axiom.Classes
+	default:
+		return nil, &godl.UnsupportedError{Span: predicate.Span, Name: predicate.Name}
+	}
+
+	res := make([]string, len(ces))
+	for i, ce := range ces {
+		class, ok := ce.(godl.Class)
+		if !ok {
+			return nil, fmt.Errorf("%s: '%s' not supported, only named classes can be added", predicate.Span, predicate.FunctionalString())
+		}
+
+		if name, ok := state.dictionary.ShortNames[class.Name]; ok {
+			res[i] = name
+		} else {
+			res[i] = class.Name
+		}
+	}
+
+	return res, nil
+}
+
+// readAxioms reads the axioms of the file state.axioms, failing on the
+// first one that cannot be added
+func readAxioms() ([]addedAxiom, error) {
+	file, err := os.Open(state.axioms)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := godl.NewAxiomReader(file)
+	axioms := make([]addedAxiom, 0)
+
+	for {
+		predicate, err := reader.Next()
+		if err == io.EOF {
+			return axioms, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s:%s", state.axioms, err)
+		}
+
+		if len(predicate.Arguments) == 0 {
+			// ontology IRI
+			continue
+		}
+
+		axiom, err := godl.NewAxiom(&predicate, reader.Namespaces())
+		if err == nil {
+			_, err = axiomClasses(axiom, &predicate)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%s", state.axioms, err)
+		}
+
+		axioms = append(axioms, addedAxiom{axiom: axiom, predicate: predicate})
+	}
+}
+
+// addAxiom adds a to the computed relation, and returns the entries of the
+// incidence matrix it sets, even when it fails halfway
+func addAxiom(a addedAxiom) ([]godl.Change, error) {
+	classes, _ := axiomClasses(a.axiom, &a.predicate)
+	changes := make([]godl.Change, 0)
+	var failed error
+
+	add := func(added []godl.Change, err error) {
+		changes = append(changes, added...)
+		if failed == nil {
+			failed = err
+		}
+	}
+
+	switch a.axiom.(type) {
+	case godl.SubClassOf:
+		add(state.relation.AddSubClassOf(classes[0], classes[1]))
+	case godl.EquivalentClasses:
+		for i := range classes {
+			for j := range classes {
+				if i != j {
+					add(state.relation.AddSubClassOf(classes[i], classes[j]))
+				}
+			}
+		}
+	case godl.DisjointClasses:
+		for i := range classes {
+			for j := i + 1; j < len(classes); j++ {
+				add(state.relation.AddDisjoint(classes[i], classes[j]))
+			}
+		}
+	}
+
+	return changes, failed
+}
+
+// addAxioms adds the axioms of the file state.axioms to the relation of a
+// database compiled already, and populates the tables of the entries of
+// the incidence matrix they set only. Nothing is added when one of the
+// axioms is not supported.
+func addAxioms() {
+	axioms, err := readAxioms()
+	if err != nil {
+		l := log.New(os.Stderr, "", 0)
+		l.Println(err)
+		os.Exit(1)
+	}
+
+	changes := make([]godl.Change, 0)
+
+	for _, a := range axioms {
+		state.relation.Source = state.axioms + ":" + a.predicate.Span.String() + ": " + a.predicate.FunctionalString()
+		added, err := addAxiom(a)
+		changes = append(changes, added...)
+
+		if err != nil {
+			log.Println("Warning:", state.axioms+":"+a.predicate.Span.String()+":", err)
+		}
+	}
+	state.relation.Source = ""
+
+	log.Println(len(changes), "new entailment(s)")
+	populateChanges(changes)
+	saveRelation()
+}
+
+// populateChanges populates the tables of the entries of the incidence
+// matrix set by changes. The tables of the subsumees being populated
+// already, the rows of Left are all the rows Right needs.
+func populateChanges(changes []godl.Change) {
+	for _, c := range changes {
+		populateTable(state.relation.Elements[c.Left], state.relation.Elements[c.Right], !c.Disjoint)
+	}
+}
+
 func initInconsistencyDegrees() {
 	state.inconsistencyDegrees = make([]float64, len(state.origins))
 }
@@ -332,6 +725,8 @@ func printStats() {
 }
 
 func main() {
+	parseFlags()
+
 	defer closeDB()
 	fmt.Println("godl-compile")
 
@@ -340,14 +735,26 @@ func main() {
 	log.Println("reading database...")
 	importOrigins()
 	importRelation()
+	importRoles()
+	importFunctionalProperties()
+	importCharacteristics()
 	importObjectPropertyNames()
+	importDataPropertyNames()
 
 	if state.stats {
 		printStats()
 	}
 
-	log.Println("populating database...")
-	populate()
+	if state.axioms != "" {
+		log.Println("adding axioms...")
+		importDictionary()
+		addAxioms()
+	} else {
+		log.Println("populating database...")
+		populateReflexiveRoles()
+		populate()
+		populateRoles()
+	}
 
 	if state.stats {
 		printStats()
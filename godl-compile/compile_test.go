@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"godl"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testTBox : the relations godl-import builds, a property P standing for
+// the elements P and P⁻ of the roles, and ∃P and ∃P⁻ of the classes, and
+// the metadata saved in __GoDL_JSON__ besides them
+type testTBox struct {
+	classes  *godl.Relation
+	roles    *godl.Relation
+	metadata map[string]interface{}
+}
+
+func newTestTBox(properties ...string) *testTBox {
+	tb := &testTBox{classes: godl.NewRelation(0), roles: godl.NewRelation(0), metadata: make(map[string]interface{})}
+	tb.classes.AutoRegister = true
+	tb.roles.AutoRegister = true
+
+	for _, p := range properties {
+		tb.classes.AddElement(p + godl.LeftSuffix)
+		tb.classes.AddElement(p + godl.RightSuffix)
+		tb.roles.AddElement(p)
+		tb.roles.AddElement(p + godl.InverseSuffix)
+	}
+
+	return tb
+}
+
+// inverse returns the element of the roles standing for role⁻
+func inverse(role string) string {
+	if strings.HasSuffix(role, godl.InverseSuffix) {
+		return strings.TrimSuffix(role, godl.InverseSuffix)
+	}
+
+	return role + godl.InverseSuffix
+}
+
+// some returns the element of the classes standing for ∃role
+func some(role string) string {
+	if strings.HasSuffix(role, godl.InverseSuffix) {
+		return strings.TrimSuffix(role, godl.InverseSuffix) + godl.RightSuffix
+	}
+
+	return role + godl.LeftSuffix
+}
+
+// subRole records sub ⊑ super as godl-import does
+func (tb *testTBox) subRole(sub string, super string) {
+	tb.roles.SetSubClassOf(sub, super)
+	tb.roles.SetSubClassOf(inverse(sub), inverse(super))
+	tb.classes.SetSubClassOf(some(sub), some(super))
+	tb.classes.SetSubClassOf(some(inverse(sub)), some(inverse(super)))
+}
+
+// open creates an in-memory database as godl-import leaves it from tb,
+// then reads it as godl-compile does. The ABox rows come from the origins
+// abox1 and abox2. It returns what is logged.
+func (tb *testTBox) open(t *testing.T) *bytes.Buffer {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection would open another database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	state.db = db
+	state.relation = godl.Relation{}
+	state.roles = godl.Relation{}
+	state.functionalProperties = nil
+	state.characteristics = nil
+	state.objectPropertyNames = nil
+	state.dataPropertyNames = nil
+
+	tb.classes.ComputeAll()
+	tb.roles.ComputeAll()
+
+	properties := make([]string, 0)
+	for _, role := range tb.roles.Elements {
+		if !strings.HasSuffix(role, godl.InverseSuffix) {
+			properties = append(properties, role)
+		}
+	}
+
+	exec(t, "CREATE TABLE '__GoDL_JSON__' (name TEXT, value TEXT);")
+
+	tboxVal, _ := tb.classes.MarshalBinary()
+	rboxVal, _ := tb.roles.MarshalBinary()
+	exec(t, "INSERT INTO __GoDL_JSON__ VALUES ('TBox', ?);", tboxVal)
+	exec(t, "INSERT INTO __GoDL_JSON__ VALUES ('RBox', ?);", rboxVal)
+
+	values := map[string]interface{}{
+		"origins":             []string{"abox1", "abox2"},
+		"objectPropertyNames": properties,
+	}
+	for name, value := range tb.metadata {
+		values[name] = value
+	}
+	for name, value := range values {
+		val, _ := json.Marshal(value)
+		exec(t, "INSERT INTO __GoDL_JSON__ VALUES (?, ?);", name, string(val))
+	}
+
+	for _, class := range tb.classes.Elements {
+		exec(t, fmt.Sprintf(`CREATE TABLE '%s' (value TEXT, positive INTEGER, weight FLOAT, origin TEXT, PRIMARY KEY (value, positive, weight, origin))`, class))
+	}
+	for _, property := range properties {
+		exec(t, fmt.Sprintf("CREATE TABLE '%s' (leftValue TEXT, rightValue TEXT, positive INTEGER, weight FLOAT, origin TEXT, PRIMARY KEY (leftValue, rightValue, positive, weight, origin))", property))
+	}
+
+	importOrigins()
+	importRelation()
+	importRoles()
+	importFunctionalProperties()
+	importCharacteristics()
+	importObjectPropertyNames()
+	importDataPropertyNames()
+
+	return &logs
+}
+
+func exec(t *testing.T, request string, args ...interface{}) {
+	t.Helper()
+
+	if _, err := state.db.Exec(request, args...); err != nil {
+		t.Fatal(request, err)
+	}
+}
+
+// compile populates the database and computes the inconsistency degrees,
+// as main does before restoring consistency
+func compile() {
+	populateReflexiveRoles()
+	populate()
+	populateRoles()
+	computeInconsistencyDegrees()
+}
+
+// expectRows checks the rows of query, their columns separated by '|'
+func expectRows(t *testing.T, query string, expected ...string) {
+	t.Helper()
+
+	rows, err := state.db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	columns, _ := rows.Columns()
+	res := make([]string, 0)
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			t.Fatal(err)
+		}
+
+		fields := make([]string, len(values))
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			fields[i] = fmt.Sprint(v)
+		}
+		res = append(res, strings.Join(fields, "|"))
+	}
+	sort.Strings(res)
+
+	if strings.Join(res, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("%s:\nexpected %q\ngot      %q", query, expected, res)
+	}
+}
+
+// expectDegrees checks the inconsistency degrees of abox1 and abox2
+func expectDegrees(t *testing.T, expected ...float64) {
+	t.Helper()
+
+	if fmt.Sprint(state.inconsistencyDegrees) != fmt.Sprint(expected) {
+		t.Errorf("expected the degrees %v, got %v", expected, state.inconsistencyDegrees)
+	}
+}
+
+// writeAxioms writes text in the file given to -a
+func writeAxioms(t *testing.T, text string) {
+	state.axioms = filepath.Join(t.TempDir(), "axioms.ofn")
+	t.Cleanup(func() { state.axioms = "" })
+
+	if err := ioutil.WriteFile(state.axioms, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddAxioms(t *testing.T) {
+	tb := newTestTBox()
+	tb.classes.SetSubClassOf("painter", "artist")
+	tb.classes.SetSubClassOf("artist", "human")
+	tb.classes.AddElement("person")
+	tb.classes.AddElement("piece")
+	logs := tb.open(t)
+
+	exec(t, "INSERT INTO painter VALUES ('picasso', 1, 0.5, 'abox1')")
+	exec(t, "INSERT INTO artist VALUES ('ravel', 1, 1, 'abox2')")
+	compile()
+
+	// human must not be populated again
+	exec(t, "DELETE FROM human WHERE value = 'ravel'")
+
+	writeAxioms(t, `Ontology(
+   SubClassOf(artist person)
+   DisjointClasses(person piece)
+   SubClassOf(artist unknown)
+)`)
+	importDictionary()
+	addAxioms()
+
+	expectRows(t, "SELECT value, positive, weight, origin FROM person", "picasso|1|0.5|abox1", "ravel|1|1|abox2")
+	expectRows(t, "SELECT value, positive FROM piece", "picasso|0", "ravel|0")
+	expectRows(t, "SELECT value FROM human", "picasso")
+
+	if !strings.Contains(logs.String(), "axioms.ofn:4:4: unknown element 'unknown'") {
+		t.Errorf("unknown class not reported:\n%s", logs)
+	}
+
+	// the relation is saved
+	state.relation = godl.Relation{}
+	importRelation()
+	r := &state.relation
+	if r.Incidence(r.IndexOf["painter"], r.IndexOf["person"]) != 1 {
+		t.Error("painter ⊑ person not saved")
+	}
+
+	// with the axioms they come from
+	explanation, err := r.Explain("painter", "person")
+	if err != nil || len(explanation) != 2 || explanation[1].Axiom != state.axioms+":2:4: SubClassOf(artist person)" {
+		t.Errorf("bad explanation %v, %v", explanation, err)
+	}
+}
+
+func TestAddUnsupportedAxioms(t *testing.T) {
+	tb := newTestTBox()
+	tb.classes.AddElement("artist")
+	tb.classes.AddElement("piece")
+	tb.open(t)
+	importDictionary()
+
+	for _, test := range []struct {
+		axiom string
+		err   string
+	}{
+		{"SubClassOf(artist piece)", ""},
+		{"ObjectPropertyDomain(hasComposed artist)", "axioms.ofn:3:4: 'ObjectPropertyDomain' not supported"},
+		{"DisjointClasses(artist ObjectComplementOf(piece))", "axioms.ofn:3:4: 'DisjointClasses(artist ObjectComplementOf(piece))' not supported, only named classes can be added"},
+		{"Declaration(Class(artist))", "axioms.ofn:3:4: 'Declaration' not supported"},
+	} {
+		writeAxioms(t, "Ontology(\n   EquivalentClasses(artist artist)\n   "+test.axiom+"\n)")
+
+		axioms, err := readAxioms()
+		switch {
+		case test.err == "" && (err != nil || len(axioms) != 2):
+			t.Errorf("%s: expected 2 axioms, got %v, %v", test.axiom, axioms, err)
+		case test.err != "" && (err == nil || !strings.HasSuffix(err.Error(), test.err)):
+			t.Errorf("%s: expected the error %q, got %v", test.axiom, test.err, err)
+		}
+	}
+}
+
+// disjointRoles records that r and s are disjoint as godl-import does
+func (tb *testTBox) disjointRoles(r string, s string) {
+	tb.roles.SetDisjointClasses(r, s)
+	tb.roles.SetDisjointClasses(inverse(r), inverse(s))
+}
+
+// assertRole inserts property(a, b) as godl-import does
+func assertRole(t *testing.T, property string, a string, b string, weight float64, origin string) {
+	exec(t, fmt.Sprintf("INSERT INTO '%s' VALUES (?, ?, 1, ?, ?)", property), a, b, weight, origin)
+	exec(t, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, 1, ?, ?)", property+godl.LeftSuffix), a, weight, origin)
+	exec(t, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, 1, ?, ?)", property+godl.RightSuffix), b, weight, origin)
+}
+
+func TestPopulateRoleInclusions(t *testing.T) {
+	tb := newTestTBox("hasSon", "hasChild", "hasRelative")
+	tb.subRole("hasSon", "hasChild")
+	tb.subRole("hasChild", "hasRelative")
+	tb.classes.SetSubClassOf(some("hasChild"), "parent")
+	tb.classes.SetSubClassOf(some(inverse("hasChild")), "child")
+	tb.open(t)
+
+	assertRole(t, "hasSon", "tom", "bob", 0.5, "abox1")
+	assertRole(t, "hasChild", "ann", "sue", 1, "abox2")
+	compile()
+
+	expectRows(t, "SELECT leftValue, rightValue, weight, origin FROM hasChild", "ann|sue|1|abox2", "tom|bob|0.5|abox1")
+	expectRows(t, "SELECT leftValue, rightValue FROM hasRelative", "ann|sue", "tom|bob")
+	expectRows(t, "SELECT leftValue, rightValue FROM hasSon", "tom|bob")
+	expectRows(t, "SELECT value FROM parent", "ann", "tom")
+	expectRows(t, "SELECT value FROM child", "bob", "sue")
+	expectDegrees(t, 0, 0)
+}
+
+func TestPopulateInverseProperties(t *testing.T) {
+	// InverseObjectProperties(hasComposed hasArtist)
+	tb := newTestTBox("hasComposed", "hasArtist")
+	tb.subRole("hasComposed", inverse("hasArtist"))
+	tb.subRole(inverse("hasArtist"), "hasComposed")
+	tb.classes.SetSubClassOf(some("hasComposed"), "artist")
+	tb.open(t)
+
+	assertRole(t, "hasArtist", "summertime", "gershwin", 0.5, "abox1")
+	assertRole(t, "hasComposed", "ravel", "bolero", 1, "abox2")
+	compile()
+
+	expectRows(t, "SELECT leftValue, rightValue, weight, origin FROM hasComposed", "gershwin|summertime|0.5|abox1", "ravel|bolero|1|abox2")
+	expectRows(t, "SELECT leftValue, rightValue, weight, origin FROM hasArtist", "bolero|ravel|1|abox2", "summertime|gershwin|0.5|abox1")
+	expectRows(t, "SELECT value FROM artist", "gershwin", "ravel")
+	expectRows(t, "SELECT value FROM '"+some("hasArtist")+"'", "bolero", "summertime")
+}
+
+func TestDisjointClassesConflicts(t *testing.T) {
+	// SubClassOf(artist ObjectComplementOf(piece))
+	tb := newTestTBox()
+	tb.classes.SetSubClassOf("painter", "artist")
+	tb.classes.SetDisjointClasses("artist", "piece")
+	tb.open(t)
+
+	exec(t, "INSERT INTO painter VALUES ('x', 1, 0.5, 'abox1')")
+	exec(t, "INSERT INTO piece VALUES ('x', 1, 0.8, 'abox2')")
+	exec(t, "INSERT INTO piece VALUES ('y', 1, 0.3, 'abox1')")
+	exec(t, "INSERT INTO piece VALUES ('z', 1, 0.9, 'abox1')")
+	compile()
+
+	expectRows(t, "SELECT value, positive, weight, origin FROM piece", "x|0|0.5|abox1", "x|1|0.8|abox2", "y|1|0.3|abox1", "z|1|0.9|abox1")
+	expectRows(t, "SELECT value, positive, weight, origin FROM artist", "x|0|0.8|abox2", "x|1|0.5|abox1", "y|0|0.3|abox1", "z|0|0.9|abox1")
+	expectDegrees(t, 0.5, 0.8)
+
+	restoreConsistancy()
+	computeInconsistencyDegrees()
+	expectDegrees(t, 0, 0)
+
+	// each origin loses its assertions up to its degree
+	expectRows(t, "SELECT value FROM piece WHERE positive", "z")
+	expectRows(t, "SELECT value FROM painter WHERE positive")
+}
+
+func TestFunctionalRoleConflicts(t *testing.T) {
+	tb := newTestTBox("hasMother", "hasBirthMother", "hasSSN")
+	tb.subRole("hasBirthMother", "hasMother")
+	tb.metadata["functionalProperties"] = []string{"hasMother", inverse("hasSSN")}
+	tb.open(t)
+
+	assertRole(t, "hasMother", "tom", "ann", 0.4, "abox1")
+	assertRole(t, "hasBirthMother", "tom", "eve", 0.9, "abox2")
+	assertRole(t, "hasMother", "bob", "ann", 0.7, "abox2")
+	assertRole(t, "hasSSN", "tom", "1", 0.2, "abox1")
+	assertRole(t, "hasSSN", "bob", "1", 0.6, "abox1")
+	compile()
+
+	expectDegrees(t, 0.6, 0.9)
+}
+
+func TestFunctionalRoleWithoutConflict(t *testing.T) {
+	tb := newTestTBox("hasMother")
+	tb.metadata["functionalProperties"] = []string{"hasMother"}
+	tb.open(t)
+
+	assertRole(t, "hasMother", "tom", "ann", 0.4, "abox1")
+	assertRole(t, "hasMother", "tom", "ann", 0.7, "abox2")
+	assertRole(t, "hasMother", "bob", "ann", 0.7, "abox2")
+	compile()
+
+	expectDegrees(t, 0, 0)
+}
+
+func TestSymmetricRoles(t *testing.T) {
+	tb := newTestTBox("marriedTo")
+	tb.subRole("marriedTo", inverse("marriedTo"))
+	tb.metadata["propertyCharacteristics"] = map[string][]string{"Symmetric": {"marriedTo"}}
+	tb.open(t)
+
+	assertRole(t, "marriedTo", "tom", "ann", 0.5, "abox1")
+	compile()
+
+	expectRows(t, "SELECT leftValue, rightValue, weight, origin FROM marriedTo", "ann|tom|0.5|abox1", "tom|ann|0.5|abox1")
+	expectRows(t, "SELECT value FROM '"+some("marriedTo")+"'", "ann", "tom")
+}
+
+func TestReflexiveRoles(t *testing.T) {
+	tb := newTestTBox("knows", "related")
+	tb.subRole("knows", "related")
+	tb.classes.SetSubClassOf(some("knows"), "person")
+	tb.metadata["propertyCharacteristics"] = map[string][]string{"Reflexive": {"knows"}}
+	tb.open(t)
+
+	assertRole(t, "knows", "tom", "ann", 0.5, "abox1")
+	assertRole(t, "related", "bob", "sue", 1, "abox2")
+	// joe is in no property assertion
+	exec(t, "INSERT INTO person VALUES ('joe', 1, 0.8, 'abox2')")
+	compile()
+
+	expectRows(t, "SELECT leftValue, rightValue, weight, origin FROM knows",
+		"ann|ann|0.5|abox1", "bob|bob|1|abox2", "joe|joe|0.8|abox2", "sue|sue|1|abox2", "tom|ann|0.5|abox1", "tom|tom|0.5|abox1")
+	expectRows(t, "SELECT leftValue, rightValue FROM related", "ann|ann", "bob|bob", "bob|sue", "joe|joe", "sue|sue", "tom|ann", "tom|tom")
+	expectRows(t, "SELECT value FROM person", "ann", "bob", "joe", "sue", "tom")
+	expectDegrees(t, 0, 0)
+}
+
+func TestIrreflexiveRoleConflicts(t *testing.T) {
+	tb := newTestTBox("hasParent", "knows", "loves")
+	tb.subRole("knows", "loves")
+	tb.metadata["propertyCharacteristics"] = map[string][]string{
+		"Irreflexive": {"hasParent", "loves"},
+		"Reflexive":   {"knows"},
+	}
+	tb.open(t)
+
+	assertRole(t, "hasParent", "tom", "tom", 0.3, "abox1")
+	// knows(bob, bob) and knows(sue, sue), so loves(bob, bob) and
+	// loves(sue, sue)
+	assertRole(t, "knows", "bob", "sue", 0.6, "abox2")
+	compile()
+
+	expectDegrees(t, 0.3, 0.6)
+}
+
+func TestDisjointRoleConflicts(t *testing.T) {
+	tb := newTestTBox("hasFather", "hasMother", "hasChild")
+	tb.disjointRoles("hasFather", "hasMother")
+	tb.disjointRoles("hasFather", inverse("hasChild"))
+	tb.open(t)
+
+	assertRole(t, "hasFather", "tom", "bob", 0.4, "abox1")
+	assertRole(t, "hasMother", "tom", "bob", 0.6, "abox2")
+	// hasFather⁻(sue, ann), within abox1
+	assertRole(t, "hasFather", "sue", "ann", 0.2, "abox1")
+	assertRole(t, "hasChild", "ann", "sue", 0.5, "abox1")
+	compile()
+
+	expectDegrees(t, 0.5, 0.6)
+}
+
+func TestDisjointRolesBothWays(t *testing.T) {
+	// the pair (hasFather, hasMother) is met as (i, j) and as (j, i): each
+	// pass must give the same degrees
+	for _, order := range [][2]string{{"hasFather", "hasMother"}, {"hasMother", "hasFather"}} {
+		tb := newTestTBox(order[0], order[1])
+		tb.disjointRoles(order[0], order[1])
+		tb.open(t)
+
+		assertRole(t, "hasFather", "tom", "bob", 0.8, "abox1")
+		assertRole(t, "hasMother", "tom", "bob", 0.2, "abox1")
+		assertRole(t, "hasMother", "ann", "bob", 0.7, "abox2")
+		compile()
+
+		expectDegrees(t, 0.8, 0)
+	}
+}
+
+func TestAsymmetricRoleConflicts(t *testing.T) {
+	tb := newTestTBox("isParentOf")
+	tb.disjointRoles("isParentOf", inverse("isParentOf"))
+	tb.metadata["propertyCharacteristics"] = map[string][]string{"Asymmetric": {"isParentOf"}}
+	tb.open(t)
+
+	assertRole(t, "isParentOf", "tom", "bob", 0.4, "abox1")
+	assertRole(t, "isParentOf", "bob", "tom", 0.7, "abox2")
+	assertRole(t, "isParentOf", "ann", "sue", 0.9, "abox2")
+	compile()
+
+	expectDegrees(t, 0.4, 0.7)
+}
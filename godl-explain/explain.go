@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"flag"
+	"fmt"
+	"godl"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Version of the tool
+var Version = "v.0.5-RC1"
+
+var state struct {
+	dirname  string
+	dbname   string
+	fullname string
+	db       *sql.DB
+	relation godl.Relation
+}
+
+func parseFlags() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "godl-explain\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n  godl-explain [arguments] db_name [class1 class2]\n\n")
+		fmt.Fprintf(os.Stderr, "Explains why class1 ⊑ class2, or why class1 and class2 are disjoint.\n")
+		fmt.Fprintf(os.Stderr, "Without classes, reads one pair per line on the standard input.\n\n")
+		fmt.Fprintf(os.Stderr, "arguments:\n")
+
+		flag.PrintDefaults()
+	}
+
+	var help bool
+	flag.BoolVar(&help, "h", false, "this message")
+
+	var version bool
+	flag.BoolVar(&version, "v", false, "version")
+
+	var list bool
+	flag.BoolVar(&list, "l", false, "list available databases")
+
+	flag.Parse()
+
+	if help {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	if version {
+		fmt.Println("godl-explain version:", Version)
+		os.Exit(0)
+	}
+
+	if list {
+		fmt.Println("\033[1mAvailable databases:\033[0m")
+		files, _ := ioutil.ReadDir(state.dirname)
+		for i, f := range files {
+			fmt.Printf("(%d) %s\t%d\n", i, f.Name(), f.Size())
+		}
+		os.Exit(0)
+	}
+
+	if flag.NArg() != 1 && flag.NArg() != 3 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	state.dbname = flag.Arg(0)
+	state.fullname = state.dirname + string(os.PathSeparator) + state.dbname
+}
+
+func openDB() {
+	var err error
+	state.db, err = sql.Open("sqlite3", state.fullname)
+	log.Println("opening database", "'"+state.fullname+"'...")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func importRelation() {
+	query := `SELECT value FROM __GoDL_JSON__ WHERE name = 'TBox';`
+	row := state.db.QueryRow(query)
+
+	var raw []byte
+	if err := row.Scan(&raw); err != nil {
+		l := log.New(os.Stderr, "", 0)
+		l.Println(err)
+		os.Exit(1)
+	}
+
+	if err := godl.DecodeRelation(raw, &state.relation); err != nil {
+		l := log.New(os.Stderr, "", 0)
+		l.Println(err)
+		os.Exit(1)
+	}
+
+	if len(state.relation.Assertions()) == 0 {
+		log.Println("Warning: no assertion recorded, import the TBox again")
+	}
+}
+
+// explain prints why class1 ⊑ class2 or why they are disjoint
+func explain(class1 string, class2 string) {
+	assertions, err := state.relation.Explain(class1, class2)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	i, j := state.relation.IndexOf[class1], state.relation.IndexOf[class2]
+	if state.relation.Incidence(i, j) == -1 {
+		fmt.Println(class1, "⊑ ¬"+class2, "because:")
+	} else {
+		fmt.Println(class1, "⊑", class2, "because:")
+	}
+
+	for _, a := range assertions {
+		fmt.Println("  ", a)
+	}
+}
+
+func init() {
+	usr, _ := user.Current()
+	state.dirname = usr.HomeDir + "/" + "GoDL"
+
+	parseFlags()
+
+	openDB()
+	importRelation()
+}
+
+func main() {
+	defer state.db.Close()
+
+	if flag.NArg() == 3 {
+		explain(flag.Arg(1), flag.Arg(2))
+		return
+	}
+
+	// the last line may have no newline
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if len(line) != 0 && line[0] != '#' {
+			if fields := strings.Fields(line); len(fields) == 2 {
+				explain(fields[0], fields[1])
+			} else {
+				log.Println("Warning: two classes expected, got", "'"+line+"'")
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
 	"godl"
+	"io"
 	"io/ioutil"
 	"log"
 	"math"
 	"math/rand"
 	"os"
 	"os/user"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -31,21 +36,41 @@ var _properties struct {
 	aboxes              []string
 	db                  *sql.DB
 	doNotImportTBox     bool
+	stopOnIncoherence   bool
 	weightGenerator     func(int) float64
+	batchSize           int
+	workers             int
+	format              string
+	tboxFormat          string
 	classNames          []string
 	objectPropertyNames []string
+	dataPropertyNames   []string
 	Debug               bool
 }
 
 type _TBoxDescriptor struct {
-	classes             []string
-	classesMap          map[string]int
-	objectProperties    []string
-	objectPropertiesMap map[string]int
-	dataProperties      []string
-	pass                uint
-	todo                map[string]int
-	relation            *godl.Relation
+	objectProperties      []string
+	dataProperties        []string
+	declared              map[string]string
+	pass                  uint
+	todo                  map[string]int
+	firstTodo             map[string]godl.Span
+	relation              *godl.Relation
+	roles                 *godl.Relation
+	qualifiedExistentials []qualifiedExistential
+	functionalProperties  []string
+	inverseProperties     [][2]string
+	characteristics       map[string][]string
+	dictionary            *godl.Dictionary
+}
+
+// qualifiedExistential : Class ⊑ ∃Property.Filler, found on the right of
+// SubClassOf and recorded for query rewriting, Property being an element
+// of the role relation
+type qualifiedExistential struct {
+	Class    string
+	Property string
+	Filler   string
 }
 
 var tbox _TBoxDescriptor
@@ -84,7 +109,7 @@ func importABoxes() bool {
 	log.Println("importing ABoxes...")
 
 	for _, fn := range _properties.aboxes {
-		bs, err := ioutil.ReadFile(fn)
+		file, err := os.Open(fn)
 
 		if err != nil {
 			l := log.New(os.Stderr, "", 0)
@@ -93,73 +118,252 @@ func importABoxes() bool {
 		}
 
 		log.Println("importing ABox", fn)
-		result := godl.Parse(string(bs))
-		importABox(&result, fn)
+		err = importABox(newABoxReader(file, fn), fn)
+		file.Close()
+
+		if err != nil {
+			l := log.New(os.Stderr, "", 0)
+			l.Println(fn + ":" + err.Error())
+			os.Exit(1)
+		}
 	}
 
 	return true
 }
 
-func importABox(predicates *godl.DLPredicate, filename string) bool {
-	db := _properties.db
-	ontology := predicates.FindOntology()
-	passed := make(map[string]int)
+// fileFormat returns the format of the file fn: the one given by -f, or
+// else the one guessed from its extension
+func fileFormat(fn string) string {
+	if _properties.format != "" {
+		return _properties.format
+	}
 
-	tx, _ := db.Begin()
-	n := 1
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".nt":
+		return "nt"
+	case ".ttl":
+		return "ttl"
+	}
 
-	for i := range ontology.Arguments {
-		weight := _properties.weightGenerator(n)
+	return "ofn"
+}
 
-		switch ontology.Arguments[i].Name {
-		case "ClassAssertion":
-			className := ontology.Arguments[i].Arguments[0].Name
-			value := ontology.Arguments[i].Arguments[1].Name
-			request := fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES ('%s', 1, %f, '%s')", className, value, weight, filename)
-			db.Exec(request)
+// tboxFormat returns the format of the TBox fn: the one given by -t, or
+// the one of its extension, or the one of its root element for XML files
+func tboxFormat(fn string, content []byte) string {
+	if _properties.tboxFormat != "" {
+		return _properties.tboxFormat
+	}
 
-			n++
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".ofn":
+		return "ofn"
+	case ".owx":
+		return "owlxml"
+	case ".rdf":
+		return "rdfxml"
+	}
 
-		case "ObjectPropertyAssertion":
-			className := ontology.Arguments[i].Arguments[0].Name
-			leftValue := ontology.Arguments[i].Arguments[1].Name
-			rightValue := ontology.Arguments[i].Arguments[2].Name
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 || content[0] != '<' {
+		return "ofn"
+	}
 
-			request := fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES ('%s', '%s', 1, %f, '%s');",
-				className, leftValue, rightValue, weight, filename)
-			db.Exec(request)
-			n++
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return "rdfxml"
+		}
 
-			request = fmt.Sprintf("INSERT OR IGNORE INTO '%s__GoDL_LEFT__' VALUES ('%s', 1, %f, '%s');",
-				className, leftValue, weight, filename)
-			db.Exec(request)
-			n++
+		if e, ok := tok.(xml.StartElement); ok {
+			if e.Name.Local == "Ontology" {
+				return "owlxml"
+			}
+			return "rdfxml"
+		}
+	}
+}
 
-			request = fmt.Sprintf("INSERT OR IGNORE INTO '%s__GoDL_RIGHT__' VALUES ('%s', 1, %f, '%s');",
-				className, rightValue, weight, filename)
-			db.Exec(request)
-			n++
+// newABoxReader returns a reader for the ABox file fn
+func newABoxReader(file io.Reader, fn string) godl.AxiomStream {
+	switch fileFormat(fn) {
+	case "nt":
+		return godl.NewNTriplesReader(file)
+	case "ttl":
+		return godl.NewTurtleReader(file)
+	}
 
-		default:
-			predicate := ontology.Arguments[i].Name
-			if _, ok := passed[predicate]; ok {
-				passed[predicate]++
-			} else {
-				passed[predicate] = 1
+	return godl.NewAxiomReader(file)
+}
+
+// addTodo counts an occurence of a construct that is not handled,
+// remembering where it first appeared
+func addTodo(todo map[string]int, first map[string]godl.Span, name string, span godl.Span) {
+	if _, ok := todo[name]; ok {
+		todo[name]++
+	} else {
+		todo[name] = 1
+		first[name] = span
+	}
+}
+
+// shortName returns the table name of the entity iri
+func shortName(iri string) string {
+	return tbox.dictionary.ShortName(iri)
+}
+
+// aboxImporter inserts the assertions of an ABox in the database
+type aboxImporter struct {
+	tx          *sql.Tx
+	filename    string
+	n           int
+	passed      map[string]int
+	firstPassed map[string]godl.Span
+	failed      map[string]int
+	firstFailed map[string]godl.Span
+}
+
+// exec runs request in the transaction, counting its errors by message
+func (ai *aboxImporter) exec(span godl.Span, request string, args ...interface{}) {
+	if _, err := ai.tx.Exec(request, args...); err != nil {
+		addTodo(ai.failed, ai.firstFailed, err.Error(), span)
+	}
+}
+
+func (ai *aboxImporter) importAssertion(assertion *godl.DLPredicate, ns *godl.Namespaces) {
+	if len(assertion.Arguments) == 0 {
+		// ontology IRI
+		return
+	}
+
+	axiom, err := godl.NewAxiom(assertion, ns)
+
+	switch err := err.(type) {
+	case nil:
+	case *godl.UnsupportedError:
+		addTodo(ai.passed, ai.firstPassed, err.Name, err.Span)
+		return
+	default:
+		log.Println("Warning:", ai.filename+":"+err.Error())
+		return
+	}
+
+	weight := _properties.weightGenerator(ai.n)
+	filename := ai.filename
+
+	switch a := axiom.(type) {
+	case godl.ClassAssertion:
+		class, ok := a.Class.(godl.Class)
+		if !ok {
+			addTodo(ai.passed, ai.firstPassed, "ClassAssertion of a complex class", assertion.Span)
+			return
+		}
+
+		className := shortName(class.Name)
+		ai.exec(assertion.Span, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, 1, ?, ?);", className),
+			a.Individual, weight, filename)
+		ai.n++
+
+	case godl.ObjectPropertyAssertion:
+		var className string
+		leftValue, rightValue := a.Subject, a.Object
+
+		switch property := a.Property.(type) {
+		case godl.ObjectProperty:
+			className = shortName(property.Name)
+		case godl.ObjectInverseOf:
+			className = shortName(property.Property.Name)
+			leftValue, rightValue = rightValue, leftValue
+		}
+
+		ai.exec(assertion.Span, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, ?, 1, ?, ?);", className),
+			leftValue, rightValue, weight, filename)
+		ai.n++
+
+		ai.exec(assertion.Span, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, 1, ?, ?);", className+godl.LeftSuffix),
+			leftValue, weight, filename)
+		ai.n++
+
+		ai.exec(assertion.Span, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, 1, ?, ?);", className+godl.RightSuffix),
+			rightValue, weight, filename)
+		ai.n++
+
+	case godl.DataPropertyAssertion:
+		className := shortName(a.Property.Name)
+		value := a.Value
+
+		ai.exec(assertion.Span, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, ?, ?, ?, 1, ?, ?);", className),
+			a.Subject, value.Value, value.Datatype, value.Lang, weight, filename)
+		ai.n++
+
+		ai.exec(assertion.Span, fmt.Sprintf("INSERT OR IGNORE INTO '%s' VALUES (?, 1, ?, ?);", className+godl.LeftSuffix),
+			a.Subject, weight, filename)
+		ai.n++
+
+	case godl.Declaration:
+
+	default:
+		addTodo(ai.passed, ai.firstPassed, assertion.Name, assertion.Span)
+	}
+}
+
+// importABox streams the assertions of reader into the database, committing
+// every _properties.batchSize assertions
+func importABox(reader godl.AxiomStream, filename string) error {
+	db := _properties.db
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ai := aboxImporter{
+		tx:          tx,
+		filename:    filename,
+		n:           1,
+		passed:      make(map[string]int),
+		firstPassed: make(map[string]godl.Span),
+		failed:      make(map[string]int),
+		firstFailed: make(map[string]godl.Span),
+	}
+	count := 0
+
+	for {
+		assertion, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			ai.tx.Rollback()
+			return err
+		}
+
+		ai.importAssertion(&assertion, reader.Namespaces())
+
+		if count++; count%_properties.batchSize == 0 {
+			if err := ai.tx.Commit(); err != nil {
+				return err
+			}
+
+			if ai.tx, err = db.Begin(); err != nil {
+				return err
 			}
 		}
 	}
-	state := tx.Commit()
 
-	if state != nil {
-		log.Panic(state)
+	if err := ai.tx.Commit(); err != nil {
+		return err
 	}
 
-	for p, occ := range passed {
-		log.Println("Warning: treatment of", "'"+p+"'", "not implemented ("+strconv.Itoa(occ), "occurences)")
+	for p, occ := range ai.passed {
+		log.Println("Warning: treatment of", "'"+p+"'", "not implemented ("+strconv.Itoa(occ), "occurences, first at "+filename+":"+ai.firstPassed[p].String()+")")
 	}
 
-	return state != nil
+	for e, occ := range ai.failed {
+		log.Println("Warning: assertions not inserted,", e, "("+strconv.Itoa(occ), "occurences, first at "+filename+":"+ai.firstFailed[e].String()+")")
+	}
+
+	return nil
 }
 
 func importTBox() bool {
@@ -172,96 +376,513 @@ func importTBox() bool {
 		l.Println(err)
 		os.Exit(1)
 	}
-	result := godl.Parse(string(bs))
+	var result godl.DLPredicate
+
+	switch tboxFormat(_properties.tbox, bs) {
+	case "rdfxml":
+		result, err = godl.ReadRDFXML(bytes.NewReader(bs))
+	case "owlxml":
+		result, err = godl.ReadOWLXML(bytes.NewReader(bs))
+	default:
+		result, err = godl.Parse(string(bs))
+	}
+
+	if err != nil {
+		l := log.New(os.Stderr, "", 0)
+		l.Println(_properties.tbox + ":" + err.Error())
+		os.Exit(1)
+	}
+
 	return ImportTBox(&result)
 }
 
+// tboxAxiom : an axiom of the TBox and the predicate it comes from
+type tboxAxiom struct {
+	axiom     godl.Axiom
+	predicate *godl.DLPredicate
+}
+
+// notBasic reports an axiom using class expressions the relation cannot hold
+func (a *tboxAxiom) notBasic() {
+	addTodo(tbox.todo, tbox.firstTodo, a.predicate.Name+" of complex classes", a.predicate.Span)
+}
+
+// isThing tells if ce is owl:Thing
+func isThing(ce godl.ClassExpression) bool {
+	class, ok := ce.(godl.Class)
+	return ok && class.Name == godl.OWLNamespace+"Thing"
+}
+
+// basicClass returns the element of the relation standing for ce: a class,
+// or ∃R for ObjectSomeValuesFrom(R owl:Thing), R being a property or its
+// inverse
+func (a *tboxAxiom) basicClass(ce godl.ClassExpression) (string, bool) {
+	switch ce := ce.(type) {
+	case godl.Class:
+		return shortName(ce.Name), true
+	case godl.ObjectSomeValuesFrom:
+		if isThing(ce.Filler) {
+			return a.leftOf(ce.Property), true
+		}
+	}
+
+	return "", false
+}
+
+func (a *tboxAxiom) basicClasses(ces []godl.ClassExpression) ([]string, bool) {
+	classes := make([]string, len(ces))
+
+	for i, ce := range ces {
+		class, ok := a.basicClass(ce)
+		if !ok {
+			return nil, false
+		}
+		classes[i] = class
+	}
+
+	return classes, true
+}
+
+// literal returns the element standing for ce, or for C when ce is ¬C,
+// and whether ce is a complement
+func (a *tboxAxiom) literal(ce godl.ClassExpression) (string, bool, bool) {
+	if complement, ok := ce.(godl.ObjectComplementOf); ok {
+		class, ok := a.basicClass(complement.Class)
+		return class, true, ok
+	}
+
+	class, ok := a.basicClass(ce)
+	return class, false, ok
+}
+
+// superClass returns the element standing for ce on the right of sub ⊑ ce.
+// For ∃R.C, it is ∃R, and sub ⊑ ∃R.C is recorded for query rewriting.
+func (a *tboxAxiom) superClass(sub string, ce godl.ClassExpression) (string, bool) {
+	some, ok := ce.(godl.ObjectSomeValuesFrom)
+	if !ok || isThing(some.Filler) {
+		return a.basicClass(ce)
+	}
+
+	filler, ok := a.basicClass(some.Filler)
+	if !ok {
+		return "", false
+	}
+
+	role, _ := a.roleOf(some.Property)
+	tbox.qualifiedExistentials = append(tbox.qualifiedExistentials, qualifiedExistential{Class: sub, Property: role, Filler: filler})
+
+	return a.leftOf(some.Property), true
+}
+
+// subClassOf records left ⊑ right in the relation
+func (a *tboxAxiom) subClassOf(left string, right string) {
+	if _, err := tbox.relation.SetSubClassOf(left, right); err != nil {
+		log.Println("Warning:", _properties.tbox+":"+a.predicate.Span.String()+":", err)
+	}
+}
+
+// disjointClasses records that left and right are disjoint in the relation
+func (a *tboxAxiom) disjointClasses(left string, right string) {
+	if _, err := tbox.relation.SetDisjointClasses(left, right); err != nil {
+		log.Println("Warning:", _properties.tbox+":"+a.predicate.Span.String()+":", err)
+	}
+}
+
+// pairwiseDisjoint records that the classes are pairwise disjoint
+func (a *tboxAxiom) pairwiseDisjoint(classes []string) {
+	for i := range classes {
+		for j := i + 1; j < len(classes); j++ {
+			a.disjointClasses(classes[i], classes[j])
+		}
+	}
+}
+
+// equivalentClasses records C1 ≡ ... ≡ Cn as Ci ⊑ Cj for every i ≠ j,
+// where A ⊑ ¬B makes A and B disjoint and ¬A ⊑ ¬B is B ⊑ A, while ¬A ⊑ B
+// cannot be expressed in DL-Lite and is left out with a warning
+func (a *tboxAxiom) equivalentClasses(ces []godl.ClassExpression) {
+	classes := make([]string, len(ces))
+	complements := make([]bool, len(ces))
+
+	for i, ce := range ces {
+		class, complement, ok := a.literal(ce)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		classes[i], complements[i] = class, complement
+	}
+
+	for i := range classes {
+		for j := range classes {
+			switch {
+			case i == j:
+			case !complements[i] && !complements[j]:
+				a.subClassOf(classes[i], classes[j])
+			case !complements[i] && complements[j]:
+				a.disjointClasses(classes[i], classes[j])
+			case complements[i] && complements[j]:
+				a.subClassOf(classes[j], classes[i])
+			default:
+				log.Println("Warning:", _properties.tbox+":"+a.predicate.Span.String()+":", "'¬"+classes[i]+" ⊑ "+classes[j]+"'", "cannot be expressed in DL-Lite, ignored")
+			}
+		}
+	}
+}
+
+// declare adds the entity name of kind kind to the TBox
+func declare(kind string, name string) {
+	if _, ok := tbox.declared[name]; ok && kind != "Class" {
+		return
+	}
+
+	switch kind {
+	case "Class":
+		tbox.relation.AddElement(name)
+	case "ObjectProperty":
+		tbox.objectProperties = append(tbox.objectProperties, name)
+		tbox.relation.AddElement(name + godl.LeftSuffix)
+		tbox.relation.AddElement(name + godl.RightSuffix)
+		tbox.roles.AddElement(name)
+		tbox.roles.AddElement(name + godl.InverseSuffix)
+	case "DataProperty":
+		tbox.dataProperties = append(tbox.dataProperties, name)
+		tbox.relation.AddElement(name + godl.LeftSuffix)
+	default:
+		tbox.pass++
+		return
+	}
+
+	tbox.declared[name] = kind
+}
+
+// use returns the short name of the property iri of kind kind, declaring
+// it with a warning if it was not
+func (a *tboxAxiom) use(kind string, iri string) string {
+	name := shortName(iri)
+
+	if _, ok := tbox.declared[name]; !ok {
+		log.Println("Warning:", kind, "'"+name+"'", "not declared, first used at "+_properties.tbox+":"+a.predicate.Span.String())
+		declare(kind, name)
+	}
+
+	return name
+}
+
+// leftOf returns the element standing for ∃R
+func (a *tboxAxiom) leftOf(pe godl.ObjectPropertyExpression) string {
+	if inverse, ok := pe.(godl.ObjectInverseOf); ok {
+		return a.use("ObjectProperty", inverse.Property.Name) + godl.RightSuffix
+	}
+
+	return a.use("ObjectProperty", pe.(godl.ObjectProperty).Name) + godl.LeftSuffix
+}
+
+// rightOf returns the element standing for ∃R⁻
+func (a *tboxAxiom) rightOf(pe godl.ObjectPropertyExpression) string {
+	if inverse, ok := pe.(godl.ObjectInverseOf); ok {
+		return a.use("ObjectProperty", inverse.Property.Name) + godl.LeftSuffix
+	}
+
+	return a.use("ObjectProperty", pe.(godl.ObjectProperty).Name) + godl.RightSuffix
+}
+
+// source returns the axiom and its position, recorded with the entries
+// of the relation it sets
+func (a *tboxAxiom) source() string {
+	s := a.predicate.FunctionalString()
+	if s == "" {
+		s = a.predicate.Name + "(...)"
+	}
+
+	return _properties.tbox + ":" + a.predicate.Span.String() + ": " + s
+}
+
+// roleOf returns the element of the role relation standing for pe, and
+// the one standing for its inverse
+func (a *tboxAxiom) roleOf(pe godl.ObjectPropertyExpression) (string, string) {
+	if inverse, ok := pe.(godl.ObjectInverseOf); ok {
+		name := a.use("ObjectProperty", inverse.Property.Name)
+		return name + godl.InverseSuffix, name
+	}
+
+	name := a.use("ObjectProperty", pe.(godl.ObjectProperty).Name)
+	return name, name + godl.InverseSuffix
+}
+
+// inverseOf returns pe⁻
+func inverseOf(pe godl.ObjectPropertyExpression) godl.ObjectPropertyExpression {
+	if inverse, ok := pe.(godl.ObjectInverseOf); ok {
+		return inverse.Property
+	}
+
+	return godl.ObjectInverseOf{Property: pe.(godl.ObjectProperty)}
+}
+
+// subObjectPropertyOf records sub ⊑ super in the role relation, with
+// sub⁻ ⊑ super⁻, ∃sub ⊑ ∃super and ∃sub⁻ ⊑ ∃super⁻ in the relation
+func (a *tboxAxiom) subObjectPropertyOf(sub godl.ObjectPropertyExpression, super godl.ObjectPropertyExpression) {
+	sub1, sub2 := a.roleOf(sub)
+	super1, super2 := a.roleOf(super)
+
+	for _, pair := range [][2]string{{sub1, super1}, {sub2, super2}} {
+		if _, err := tbox.roles.SetSubClassOf(pair[0], pair[1]); err != nil {
+			log.Println("Warning:", _properties.tbox+":"+a.predicate.Span.String()+":", err)
+		}
+	}
+
+	a.subClassOf(a.leftOf(sub), a.leftOf(super))
+	a.subClassOf(a.rightOf(sub), a.rightOf(super))
+}
+
+// disjointObjectProperties records that pe1 and pe2 are disjoint in the
+// role relation, with pe1⁻ and pe2⁻
+func (a *tboxAxiom) disjointObjectProperties(pe1 godl.ObjectPropertyExpression, pe2 godl.ObjectPropertyExpression) {
+	role1, inverse1 := a.roleOf(pe1)
+	role2, inverse2 := a.roleOf(pe2)
+
+	for _, pair := range [][2]string{{role1, role2}, {inverse1, inverse2}} {
+		if _, err := tbox.roles.SetDisjointClasses(pair[0], pair[1]); err != nil {
+			log.Println("Warning:", _properties.tbox+":"+a.predicate.Span.String()+":", err)
+		}
+	}
+}
+
+// characteristic records that pe has the characteristic kind, Symmetric,
+// Asymmetric, Reflexive or Irreflexive
+func (a *tboxAxiom) characteristic(kind string, pe godl.ObjectPropertyExpression) {
+	role, _ := a.roleOf(pe)
+	tbox.characteristics[kind] = append(tbox.characteristics[kind], role)
+}
+
+// checkReflexivity warns about the reflexive roles below irreflexive ones
+func checkReflexivity() {
+	roles := tbox.roles
+
+	for _, reflexive := range tbox.characteristics["Reflexive"] {
+		for _, irreflexive := range tbox.characteristics["Irreflexive"] {
+			if roles.Incidence(roles.IndexOf[reflexive], roles.IndexOf[irreflexive]) == 1 {
+				log.Println("Warning: role", "'"+reflexive+"'", "is reflexive and below", "'"+irreflexive+"',", "which is irreflexive")
+			}
+		}
+	}
+}
+
+// apply records the axiom in the relation
+func (a *tboxAxiom) apply() {
+	tbox.relation.Source = a.source()
+	tbox.roles.Source = tbox.relation.Source
+
+	switch axiom := a.axiom.(type) {
+	case godl.Declaration:
+		declare(axiom.Kind, shortName(axiom.Name))
+	case godl.SubClassOf:
+		left, ok := a.basicClass(axiom.Sub)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		if complement, ok := axiom.Super.(godl.ObjectComplementOf); ok {
+			// A ⊑ ¬B: A and B are disjoint
+			right, ok := a.basicClass(complement.Class)
+			if !ok {
+				a.notBasic()
+				return
+			}
+			a.disjointClasses(left, right)
+			return
+		}
+		right, ok := a.superClass(left, axiom.Super)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		a.subClassOf(left, right)
+	case godl.DisjointClasses:
+		classes, ok := a.basicClasses(axiom.Classes)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		a.pairwiseDisjoint(classes)
+	case godl.EquivalentClasses:
+		a.equivalentClasses(axiom.Classes)
+	case godl.DisjointUnion:
+		classes, ok := a.basicClasses(axiom.Classes)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		union := shortName(axiom.Class.Name)
+		for _, class := range classes {
+			a.subClassOf(class, union)
+		}
+		a.pairwiseDisjoint(classes)
+		log.Println("Warning:", _properties.tbox+":"+a.predicate.Span.String()+":", "'"+union+" ⊑ "+strings.Join(classes, " ⊔ ")+"'", "cannot be expressed in DL-Lite, ignored")
+	case godl.ObjectPropertyDomain:
+		right, ok := a.basicClass(axiom.Domain)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		a.subClassOf(a.leftOf(axiom.Property), right)
+	case godl.ObjectPropertyRange:
+		right, ok := a.basicClass(axiom.Range)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		a.subClassOf(a.rightOf(axiom.Property), right)
+	case godl.DataPropertyDomain:
+		right, ok := a.basicClass(axiom.Domain)
+		if !ok {
+			a.notBasic()
+			return
+		}
+		a.subClassOf(a.use("DataProperty", axiom.Property.Name)+godl.LeftSuffix, right)
+	case godl.FunctionalObjectProperty:
+		role, _ := a.roleOf(axiom.Property)
+		tbox.functionalProperties = append(tbox.functionalProperties, role)
+	case godl.InverseFunctionalObjectProperty:
+		_, role := a.roleOf(axiom.Property)
+		tbox.functionalProperties = append(tbox.functionalProperties, role)
+	case godl.SymmetricObjectProperty:
+		a.characteristic("Symmetric", axiom.Property)
+		a.subObjectPropertyOf(axiom.Property, inverseOf(axiom.Property))
+	case godl.AsymmetricObjectProperty:
+		// R and R⁻ disjoint
+		a.characteristic("Asymmetric", axiom.Property)
+		a.disjointObjectProperties(axiom.Property, inverseOf(axiom.Property))
+	case godl.ReflexiveObjectProperty:
+		a.characteristic("Reflexive", axiom.Property)
+	case godl.IrreflexiveObjectProperty:
+		a.characteristic("Irreflexive", axiom.Property)
+	case godl.DisjointObjectProperties:
+		for i := range axiom.Properties {
+			for j := i + 1; j < len(axiom.Properties); j++ {
+				a.disjointObjectProperties(axiom.Properties[i], axiom.Properties[j])
+			}
+		}
+	case godl.SubObjectPropertyOf:
+		a.subObjectPropertyOf(axiom.Sub, axiom.Super)
+	case godl.InverseObjectProperties:
+		// First ≡ Second⁻, so ∃First ≡ ∃Second⁻ and ∃First⁻ ≡ ∃Second
+		first, _ := a.roleOf(axiom.First)
+		second, _ := a.roleOf(axiom.Second)
+		tbox.inverseProperties = append(tbox.inverseProperties, [2]string{first, second})
+		a.subObjectPropertyOf(axiom.First, inverseOf(axiom.Second))
+		a.subObjectPropertyOf(inverseOf(axiom.Second), axiom.First)
+	default:
+		addTodo(tbox.todo, tbox.firstTodo, a.predicate.Name, a.predicate.Span)
+	}
+}
+
 // ImportTBox imports the TBOxes described in predicates
 func ImportTBox(predicates *godl.DLPredicate) bool {
-	tbox.classes = make([]string, 0)
 	tbox.objectProperties = make([]string, 0)
+	tbox.dataProperties = make([]string, 0)
+	tbox.declared = make(map[string]string)
 	tbox.todo = make(map[string]int)
+	tbox.firstTodo = make(map[string]godl.Span)
+	tbox.dictionary = godl.NewDictionary()
 
 	ontology := predicates.FindOntology()
+	filename := _properties.tbox
 
-	for i := range ontology.Arguments {
-		if ontology.Arguments[i].Name == "Declaration" {
-			declaration := &ontology.Arguments[i].Arguments[0]
-
-			switch declaration.Name {
-			case "Class":
-				tbox.classes = append(tbox.classes, declaration.Arguments[0].Name)
-			case "ObjectProperty":
-				tbox.objectProperties = append(tbox.objectProperties, declaration.Arguments[0].Name)
-				tbox.classes = append(tbox.classes, declaration.Arguments[0].Name+"__GoDL_LEFT__")
-				tbox.classes = append(tbox.classes, declaration.Arguments[0].Name+"__GoDL_RIGHT__")
-			case "DataProperty":
-				tbox.dataProperties = append(tbox.dataProperties, declaration.Arguments[0].Name)
+	if ontology == nil {
+		log.Println("Warning:", filename, "contains no ontology")
+		return false
+	}
 
-			default:
-				tbox.pass++
-			}
-		}
+	ns, err := predicates.Namespaces()
+	if err != nil {
+		log.Println("Warning:", filename+":"+err.Error())
 	}
 
-	tbox.relation = godl.NewRelation(len(tbox.classes))
+	tbox.qualifiedExistentials = make([]qualifiedExistential, 0)
+	tbox.functionalProperties = make([]string, 0)
+	tbox.inverseProperties = make([][2]string, 0)
+	tbox.characteristics = make(map[string][]string)
+	tbox.relation = godl.NewRelation(0)
+	tbox.relation.AutoRegister = true
+	tbox.roles = godl.NewRelation(0)
+	tbox.relation.Workers = _properties.workers
+	tbox.roles.Workers = _properties.workers
 	if _properties.Debug {
 		tbox.relation.Debug = true
+		tbox.roles.Debug = true
 	}
 
-	for _, e := range tbox.classes {
-		tbox.relation.AddElement(e)
-	}
+	// the declarations first, as they often come after the axioms using
+	// the entities
+	axioms := make([]tboxAxiom, 0, len(ontology.Arguments))
 
 	for i := range ontology.Arguments {
 		predicate := &ontology.Arguments[i]
 
-		switch predicate.Name {
-		case "SubClassOf":
-			left := predicate.Arguments[0].Name
-			right := predicate.Arguments[1].Name
-			tbox.relation.SetSubClassOf(left, right)
-		case "DisjointClasses":
-			left := predicate.Arguments[0].Name
-			right := predicate.Arguments[1].Name
-			tbox.relation.SetDisjointClasses(left, right)
-		case "EquivalentClasses":
-			left := predicate.Arguments[0].Name
-			right := predicate.Arguments[1].Name
-			tbox.relation.SetSubClassOf(left, right)
-			tbox.relation.SetSubClassOf(right, left)
-		case "ObjectComplementOf":
-			log.Panic("Not implemented")
-		case "ObjectPropertyDomain":
-			left := predicate.Arguments[0].Name + "__GoDL_LEFT__"
-			right := predicate.Arguments[1].Name
-			tbox.relation.SetSubClassOf(left, right)
-		case "ObjectPropertyRange":
-			left := predicate.Arguments[0].Name + "__GoDL_RIGHT__"
-			right := predicate.Arguments[1].Name
-			tbox.relation.SetSubClassOf(left, right)
-		case "Declaration":
+		if len(predicate.Arguments) == 0 {
+			// ontology IRI
+			continue
+		}
+
+		axiom, err := godl.NewAxiom(predicate, ns)
+
+		switch err := err.(type) {
+		case nil:
+		case *godl.UnsupportedError:
+			addTodo(tbox.todo, tbox.firstTodo, err.Name, err.Span)
+			continue
 		default:
-			n := ontology.Arguments[i].Name
-			if _, ok := tbox.todo[n]; ok {
-				tbox.todo[n]++
-			} else {
-				tbox.todo[n] = 1
-			}
+			log.Println("Warning:", filename+":"+err.Error())
+			continue
+		}
+
+		a := tboxAxiom{axiom: axiom, predicate: predicate}
+		if _, ok := axiom.(godl.Declaration); ok {
+			a.apply()
+		} else {
+			axioms = append(axioms, a)
 		}
 	}
 
-	tbox.relation.ComputeAll()
+	for i := range axioms {
+		axioms[i].apply()
+	}
+
+	tbox.relation.Source = ""
+	tbox.roles.Source = ""
+
+	for _, class := range tbox.relation.Undeclared() {
+		log.Println("Warning: class", "'"+class+"'", "not declared")
+	}
+
+	for _, u := range tbox.roles.ComputeAll().Unsatisfiable {
+		log.Println("Warning: role", u)
+	}
+	checkReflexivity()
+
+	report := tbox.relation.ComputeAll()
+	for _, u := range report.Unsatisfiable {
+		log.Println("Warning:", u)
+	}
+
+	if !report.Coherent() && _properties.stopOnIncoherence {
+		l := log.New(os.Stderr, "", 0)
+		l.Println(filename+":", len(report.Unsatisfiable), "unsatisfiable class(es), stopping")
+		closeDB()
+		destroyDB()
+		os.Exit(1)
+	}
 
 	for n, v := range tbox.todo {
-		log.Println("Warning,", n, "not implemented ("+strconv.Itoa(v), "occurences)")
+		log.Println("Warning,", n, "not implemented ("+strconv.Itoa(v), "occurences, first at "+filename+":"+tbox.firstTodo[n].String()+")")
 	}
 
 	// create tables
 	log.Println("creating tables...")
 	db := _properties.db
 
-	for _, class := range tbox.classes {
+	for _, class := range tbox.relation.Elements {
 		_properties.classNames = append(_properties.classNames, class)
 
 		request := fmt.Sprintf(`CREATE TABLE '%s'
@@ -282,6 +903,15 @@ func ImportTBox(predicates *godl.DLPredicate) bool {
 		}
 	}
 
+	for _, dataProperty := range tbox.dataProperties {
+		_properties.dataPropertyNames = append(_properties.dataPropertyNames, dataProperty)
+
+		request := fmt.Sprintf("CREATE TABLE '%s' (leftValue TEXT, rightValue TEXT, datatype TEXT, lang TEXT, positive INTEGER, weight FLOAT, origin TEXT, PRIMARY KEY (leftValue, rightValue, datatype, lang, positive, weight, origin))", dataProperty)
+		if _, err := db.Exec(request); err != nil {
+			log.Println("Warning:", err)
+		}
+	}
+
 	if tbox.pass > 0 {
 		log.Println("Warning:", tbox.pass, "passed erguments...")
 	}
@@ -293,13 +923,64 @@ func ImportTBox(predicates *godl.DLPredicate) bool {
 }
 
 func saveTBox() {
-	val, _ := tbox.relation.JSON()
-	requestJSON := fmt.Sprintf("INSERT INTO  __GoDL_JSON__ VALUES ('TBox', '%s');", val)
-	if _, err := _properties.db.Exec(requestJSON); err != nil {
+	val, _ := tbox.relation.MarshalBinary()
+	if _, err := _properties.db.Exec("INSERT INTO  __GoDL_JSON__ VALUES ('TBox', ?);", val); err != nil {
+		log.Fatal(err)
+	}
+
+	val, _ = tbox.roles.MarshalBinary()
+	if _, err := _properties.db.Exec("INSERT INTO  __GoDL_JSON__ VALUES ('RBox', ?);", val); err != nil {
+		log.Fatal(err)
+	}
+
+	val, _ = json.Marshal(tbox.qualifiedExistentials)
+	if _, err := _properties.db.Exec("INSERT INTO  __GoDL_JSON__ VALUES ('qualifiedExistentials', ?);", string(val)); err != nil {
+		log.Fatal(err)
+	}
+
+	// R⁻ being functional when R is inverse functional
+	val, _ = json.Marshal(tbox.functionalProperties)
+	if _, err := _properties.db.Exec("INSERT INTO  __GoDL_JSON__ VALUES ('functionalProperties', ?);", string(val)); err != nil {
+		log.Fatal(err)
+	}
+
+	val, _ = json.Marshal(tbox.inverseProperties)
+	if _, err := _properties.db.Exec("INSERT INTO  __GoDL_JSON__ VALUES ('inverseProperties', ?);", string(val)); err != nil {
+		log.Fatal(err)
+	}
+
+	val, _ = json.Marshal(tbox.characteristics)
+	if _, err := _properties.db.Exec("INSERT INTO  __GoDL_JSON__ VALUES ('propertyCharacteristics', ?);", string(val)); err != nil {
+		log.Fatal(err)
+	}
+
+	val, _ = json.Marshal(tbox.dictionary.IRIs)
+	if _, err := _properties.db.Exec("INSERT INTO  __GoDL_JSON__ VALUES ('IRIs', ?);", string(val)); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// loadDictionary reads the short names of a TBox already imported
+func loadDictionary() {
+	tbox.dictionary = godl.NewDictionary()
+
+	var raw string
+	row := _properties.db.QueryRow(`SELECT value FROM __GoDL_JSON__ WHERE name = 'IRIs';`)
+	if err := row.Scan(&raw); err != nil {
+		log.Println("Warning: no IRI found in database,", err)
+		return
+	}
+
+	iris := make(map[string]string)
+	if err := json.Unmarshal([]byte(raw), &iris); err != nil {
+		log.Fatal(err)
+	}
+
+	for name, iri := range iris {
+		tbox.dictionary.Add(name, iri)
+	}
+}
+
 func saveOrigins() {
 	val, _ := json.Marshal(_properties.aboxes)
 	requestJSON := fmt.Sprintf("INSERT INTO  __GoDL_JSON__ VALUES ('origins', '%s');", val)
@@ -319,9 +1000,13 @@ func saveNames() {
 	requestJSON = fmt.Sprintf("INSERT INTO  __GoDL_JSON__ VALUES ('objectPropertyNames', '%s');", val)
 	if _, err := _properties.db.Exec(requestJSON); err != nil {
 		log.Fatal(err)
-
 	}
 
+	val, _ = json.Marshal(_properties.dataPropertyNames)
+	requestJSON = fmt.Sprintf("INSERT INTO  __GoDL_JSON__ VALUES ('dataPropertyNames', '%s');", val)
+	if _, err := _properties.db.Exec(requestJSON); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func randomGenerator(n int) float64 {
@@ -366,6 +1051,16 @@ func parseFlags() {
 
 	flag.BoolVar(&_properties.Debug, "g", false, "add some debug output")
 
+	flag.BoolVar(&_properties.stopOnIncoherence, "c", false, "stop when the TBox has unsatisfiable classes")
+
+	flag.IntVar(&_properties.batchSize, "b", 100000, "number of assertions per transaction")
+
+	flag.IntVar(&_properties.workers, "j", 0, "number of goroutines classifying the TBox, one per CPU by default")
+
+	flag.StringVar(&_properties.format, "f", "", "format of the ABoxes (ofn: functional syntax, nt: N-Triples, ttl: Turtle), guessed from the extension by default")
+
+	flag.StringVar(&_properties.tboxFormat, "t", "", "format of the TBox (ofn: functional syntax, rdfxml: RDF/XML, owlxml: OWL/XML), guessed from the file by default")
+
 	var computeWeigthMethod int
 	flag.IntVar(&computeWeigthMethod, "w", 0, "compute Weigths (0: all 1, 1: random, 2: decreasing order, 3: increasing number, 4: all NaN)")
 
@@ -383,6 +1078,26 @@ func parseFlags() {
 		os.Exit(1)
 	}
 
+	switch _properties.format {
+	case "", "ofn", "nt", "ttl":
+	default:
+		fmt.Fprintln(os.Stderr, "unknown format:", _properties.format)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	switch _properties.tboxFormat {
+	case "", "ofn", "rdfxml", "owlxml":
+	default:
+		fmt.Fprintln(os.Stderr, "unknown TBox format:", _properties.tboxFormat)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if _properties.batchSize < 1 {
+		_properties.batchSize = 1
+	}
+
 	if dbname != "" {
 		_properties.dbname = dbname
 		_properties.fullname = _properties.dirname + string(os.PathSeparator) + _properties.dbname
@@ -437,6 +1152,8 @@ func main() {
 
 	if !_properties.doNotImportTBox {
 		importTBox()
+	} else {
+		loadDictionary()
 	}
 
 	importABoxes()
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"godl"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// importTest imports the TBox tboxText, then the ABoxes abox1, abox2...,
+// into an in-memory database, and returns what was logged
+func importTest(t *testing.T, tboxText string, aboxes ...string) string {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection would open another database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("CREATE TABLE '__GoDL_JSON__' (name TEXT, value TEXT);"); err != nil {
+		t.Fatal(err)
+	}
+
+	_properties.db = db
+	_properties.tbox = "tbox.ofn"
+	_properties.weightGenerator = constantGenerator
+	_properties.batchSize = 2
+	_properties.classNames = make([]string, 0)
+	_properties.objectPropertyNames = make([]string, 0)
+	_properties.dataPropertyNames = make([]string, 0)
+
+	predicates, err := godl.Parse(tboxText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ImportTBox(&predicates) {
+		t.Fatal("TBox not imported")
+	}
+
+	for i, abox := range aboxes {
+		if err := importABox(godl.NewAxiomReader(strings.NewReader(abox)), fmt.Sprint("abox", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return logs.String()
+}
+
+// queryRows returns the rows of query, their columns separated by '|',
+// sorted
+func queryRows(t *testing.T, query string) []string {
+	rows, err := _properties.db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	columns, _ := rows.Columns()
+	res := make([]string, 0)
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			t.Fatal(err)
+		}
+
+		fields := make([]string, len(values))
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			fields[i] = fmt.Sprint(v)
+		}
+		res = append(res, strings.Join(fields, "|"))
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+func expectRows(t *testing.T, query string, expected ...string) {
+	t.Helper()
+
+	if rows := queryRows(t, query); strings.Join(rows, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("%s:\nexpected %q\ngot      %q", query, expected, rows)
+	}
+}
+
+func TestImportQuotedIndividuals(t *testing.T) {
+	logs := importTest(t, `Ontology(
+   Declaration(Class(artist))
+   Declaration(ObjectProperty(hasComposed))
+)`, `Ontology(
+   ClassAssertion(artist <http://example.org/o'brien>)
+   ObjectPropertyAssertion(hasComposed <http://example.org/o'brien> <http://example.org/don't>)
+   ClassAssertion(painter <http://example.org/o'brien>)
+)`)
+
+	expectRows(t, "SELECT value, origin FROM artist", "http://example.org/o'brien|abox1")
+	expectRows(t, "SELECT leftValue, rightValue FROM hasComposed", "http://example.org/o'brien|http://example.org/don't")
+	expectRows(t, "SELECT value FROM hasComposed"+godl.RightSuffix, "http://example.org/don't")
+
+	if !strings.Contains(logs, "no such table: painter (1 occurences, first at abox1:4:") {
+		t.Errorf("failed insertion not reported:\n%s", logs)
+	}
+}
+
+func TestImportDeclarationsLast(t *testing.T) {
+	logs := importTest(t, `Ontology(
+   SubClassOf(painter artist)
+   ObjectPropertyDomain(hasComposed artist)
+   ObjectPropertyRange(hasPainted painter)
+   Declaration(Class(painter))
+   Declaration(Class(artist))
+   Declaration(ObjectProperty(hasComposed))
+)`)
+
+	if strings.Contains(logs, "'hasComposed' not declared") || strings.Contains(logs, "class 'painter' not declared") {
+		t.Errorf("declared entities reported:\n%s", logs)
+	}
+
+	if !strings.Contains(logs, "ObjectProperty 'hasPainted' not declared, first used at tbox.ofn:4:") {
+		t.Errorf("undeclared property not reported:\n%s", logs)
+	}
+
+	r := tbox.relation
+	if r.Incidence(r.IndexOf["hasComposed"+godl.LeftSuffix], r.IndexOf["artist"]) != 1 {
+		t.Error("∃hasComposed ⊑ artist not recorded")
+	}
+}
+
+// savedRelation reads back the relation name saved in the database
+func savedRelation(t *testing.T, name string) *godl.Relation {
+	var raw []byte
+	if err := _properties.db.QueryRow("SELECT value FROM __GoDL_JSON__ WHERE name = ?;", name).Scan(&raw); err != nil {
+		t.Fatal(name, err)
+	}
+
+	var r godl.Relation
+	if err := godl.DecodeRelation(raw, &r); err != nil {
+		t.Fatal(name, err)
+	}
+
+	return &r
+}
+
+// expectEntailed checks the entailments of r, given as "A ⊑ B" or "A ⊑ ¬B"
+func expectEntailed(t *testing.T, r *godl.Relation, entailed ...string) {
+	t.Helper()
+
+	for _, e := range entailed {
+		sides := strings.Split(e, " ⊑ ")
+		left, right, expected := sides[0], strings.TrimPrefix(sides[1], "¬"), 1
+		if strings.HasPrefix(sides[1], "¬") {
+			expected = -1
+		}
+
+		i, ok1 := r.IndexOf[left]
+		j, ok2 := r.IndexOf[right]
+		if !ok1 || !ok2 || r.Incidence(i, j) != expected {
+			t.Errorf("%s not entailed", e)
+		}
+	}
+}
+
+func TestImportRoleInclusions(t *testing.T) {
+	importTest(t, `Ontology(
+   Declaration(ObjectProperty(hasSon))
+   Declaration(ObjectProperty(hasChild))
+   Declaration(Class(parent))
+   SubObjectPropertyOf(hasSon hasChild)
+   SubObjectPropertyOf(ObjectInverseOf(hasChild) hasParent)
+   ObjectPropertyDomain(hasChild parent)
+)`)
+
+	roles := savedRelation(t, "RBox")
+	expectEntailed(t, roles,
+		"hasSon ⊑ hasChild",
+		"hasSon"+godl.InverseSuffix+" ⊑ hasChild"+godl.InverseSuffix,
+		"hasChild"+godl.InverseSuffix+" ⊑ hasParent",
+		"hasSon"+godl.InverseSuffix+" ⊑ hasParent",
+		"hasChild ⊑ hasParent"+godl.InverseSuffix)
+
+	classes := savedRelation(t, "TBox")
+	expectEntailed(t, classes,
+		"hasSon"+godl.LeftSuffix+" ⊑ hasChild"+godl.LeftSuffix,
+		"hasSon"+godl.LeftSuffix+" ⊑ parent",
+		"hasSon"+godl.RightSuffix+" ⊑ hasChild"+godl.RightSuffix,
+		"hasChild"+godl.RightSuffix+" ⊑ hasParent"+godl.LeftSuffix,
+		"hasChild"+godl.LeftSuffix+" ⊑ hasParent"+godl.RightSuffix)
+}
+
+func TestImportInverseProperties(t *testing.T) {
+	importTest(t, `Ontology(
+   Declaration(ObjectProperty(hasComposed))
+   Declaration(ObjectProperty(hasArtist))
+   Declaration(ObjectProperty(isPlayedBy))
+   Declaration(ObjectProperty(plays))
+   InverseObjectProperties(hasComposed hasArtist)
+   InverseObjectProperties(ObjectInverseOf(isPlayedBy) ObjectInverseOf(plays))
+)`)
+
+	expectRows(t, "SELECT value FROM __GoDL_JSON__ WHERE name = 'inverseProperties'",
+		`[["hasComposed","hasArtist"],["isPlayedBy`+godl.InverseSuffix+`","plays`+godl.InverseSuffix+`"]]`)
+
+	roles := savedRelation(t, "RBox")
+	expectEntailed(t, roles,
+		"hasComposed ⊑ hasArtist"+godl.InverseSuffix,
+		"hasArtist"+godl.InverseSuffix+" ⊑ hasComposed",
+		"hasArtist ⊑ hasComposed"+godl.InverseSuffix,
+		"isPlayedBy ⊑ plays"+godl.InverseSuffix,
+		"plays ⊑ isPlayedBy"+godl.InverseSuffix)
+
+	classes := savedRelation(t, "TBox")
+	expectEntailed(t, classes,
+		"hasComposed"+godl.LeftSuffix+" ⊑ hasArtist"+godl.RightSuffix,
+		"hasArtist"+godl.RightSuffix+" ⊑ hasComposed"+godl.LeftSuffix,
+		"hasComposed"+godl.RightSuffix+" ⊑ hasArtist"+godl.LeftSuffix,
+		"hasArtist"+godl.LeftSuffix+" ⊑ hasComposed"+godl.RightSuffix)
+}
+
+func TestImportQualifiedExistentials(t *testing.T) {
+	logs := importTest(t, `Ontology(
+   Declaration(Class(composer))
+   Declaration(Class(piece))
+   Declaration(Class(artist))
+   Declaration(ObjectProperty(hasComposed))
+   SubClassOf(composer ObjectSomeValuesFrom(hasComposed piece))
+   SubClassOf(piece ObjectSomeValuesFrom(ObjectInverseOf(hasComposed) composer))
+   SubClassOf(ObjectSomeValuesFrom(hasComposed owl:Thing) artist)
+   SubClassOf(composer ObjectSomeValuesFrom(hasComposed ObjectSomeValuesFrom(hasComposed piece)))
+)`)
+
+	expectRows(t, "SELECT value FROM __GoDL_JSON__ WHERE name = 'qualifiedExistentials'",
+		`[{"Class":"composer","Property":"hasComposed","Filler":"piece"},{"Class":"piece","Property":"hasComposed`+godl.InverseSuffix+`","Filler":"composer"}]`)
+
+	expectEntailed(t, savedRelation(t, "TBox"),
+		"composer ⊑ hasComposed"+godl.LeftSuffix,
+		"composer ⊑ artist",
+		"piece ⊑ hasComposed"+godl.RightSuffix)
+
+	if !strings.Contains(logs, "SubClassOf of complex classes not implemented (1 occurences, first at tbox.ofn:9:4)") {
+		t.Errorf("nested existential not reported:\n%s", logs)
+	}
+}
+
+func TestImportEquivalentComplements(t *testing.T) {
+	logs := importTest(t, `Ontology(
+   Declaration(Class(living))
+   Declaration(Class(dead))
+   Declaration(Class(mortal))
+   Declaration(Class(immortal))
+   Declaration(Class(human))
+   EquivalentClasses(living ObjectComplementOf(dead))
+   EquivalentClasses(ObjectComplementOf(mortal) ObjectComplementOf(human))
+)`)
+
+	expectEntailed(t, savedRelation(t, "TBox"),
+		"living ⊑ ¬dead",
+		"dead ⊑ ¬living",
+		"mortal ⊑ human",
+		"human ⊑ mortal")
+
+	if !strings.Contains(logs, "tbox.ofn:7:4: '¬dead ⊑ living' cannot be expressed in DL-Lite, ignored") {
+		t.Errorf("inexpressible half not reported:\n%s", logs)
+	}
+
+	if strings.Contains(logs, "complex classes") || strings.Contains(logs, "'¬mortal") {
+		t.Errorf("unexpected warning:\n%s", logs)
+	}
+}
+
+func TestImportComplementAsDisjointness(t *testing.T) {
+	importTest(t, `Ontology(
+   Declaration(Class(painter))
+   Declaration(Class(artist))
+   Declaration(Class(piece))
+   Declaration(ObjectProperty(hasComposed))
+   SubClassOf(painter artist)
+   SubClassOf(artist ObjectComplementOf(piece))
+   SubClassOf(piece ObjectComplementOf(ObjectSomeValuesFrom(hasComposed owl:Thing)))
+)`, `Ontology(
+   ClassAssertion(painter picasso)
+)`)
+
+	expectEntailed(t, savedRelation(t, "TBox"),
+		"artist ⊑ ¬piece",
+		"painter ⊑ ¬piece",
+		"piece ⊑ ¬artist",
+		"piece ⊑ ¬hasComposed"+godl.LeftSuffix)
+
+	expectRows(t, "SELECT value FROM painter", "picasso")
+}
+
+func TestImportNaryAxioms(t *testing.T) {
+	logs := importTest(t, `Ontology(
+   Declaration(Class(a))
+   Declaration(Class(b))
+   Declaration(Class(c))
+   Declaration(Class(d))
+   Declaration(Class(e))
+   Declaration(Class(f))
+   Declaration(Class(u))
+   EquivalentClasses(a b c)
+   DisjointClasses(d e f)
+   DisjointUnion(u d e)
+)`)
+
+	expectEntailed(t, savedRelation(t, "TBox"),
+		"a ⊑ b", "a ⊑ c", "b ⊑ a", "b ⊑ c", "c ⊑ a", "c ⊑ b",
+		"d ⊑ ¬e", "d ⊑ ¬f", "e ⊑ ¬f",
+		"d ⊑ u", "e ⊑ u")
+
+	if !strings.Contains(logs, "tbox.ofn:11:4: 'u ⊑ d ⊔ e' cannot be expressed in DL-Lite, ignored") {
+		t.Errorf("DisjointUnion covering not reported:\n%s", logs)
+	}
+}
+
+func TestImportFunctionalProperties(t *testing.T) {
+	importTest(t, `Ontology(
+   Declaration(ObjectProperty(hasMother))
+   Declaration(ObjectProperty(hasSSN))
+   FunctionalObjectProperty(hasMother)
+   InverseFunctionalObjectProperty(hasSSN)
+   FunctionalObjectProperty(ObjectInverseOf(isMotherOf))
+)`)
+
+	expectRows(t, "SELECT value FROM __GoDL_JSON__ WHERE name = 'functionalProperties'",
+		`["hasMother","hasSSN`+godl.InverseSuffix+`","isMotherOf`+godl.InverseSuffix+`"]`)
+}
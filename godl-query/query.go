@@ -3,8 +3,10 @@ package main
 import (
 	"bufio"
 	"database/sql"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"godl"
 	"io"
 	"io/ioutil"
 	"log"
@@ -27,6 +29,8 @@ var state struct {
 	db       *sql.DB
 	reQ      *regexp.Regexp
 	reArgs   *regexp.Regexp
+
+	dataProperties map[string]bool
 }
 
 type predicate struct {
@@ -79,7 +83,7 @@ func parsePredicate(rawS []string) predicate {
 		if p.args[i][0] == '?' {
 			p.nbVariables++
 
-			if p.name != "q" && (p.nbVariables > 1 && p.nbUnderscore != 1) {
+			if p.name != "q" && (p.nbVariables > 1 && p.nbUnderscore != 1) && !state.dataProperties[p.name] {
 				fmt.Println(p)
 				log.Fatal("Fatal error (not in fragment): '", p.name, "'")
 			}
@@ -119,14 +123,60 @@ func firstOccurenceOf(variable string, predicates []predicate) (pRes predicate,
 	return pRes, -1 //-1, ""
 }
 
+// isDataAtom tells if p is an atom over a data property
+func isDataAtom(p predicate) bool {
+	return p.arity == 2 && state.dataProperties[p.name]
+}
+
+// literalColumn returns the SQL expression writing the values of the data
+// property table tbn as literals
+func literalColumn(tbn string) string {
+	value := fmt.Sprintf(`'"' || replace(replace('%s'.rightValue, '\', '\\'), '"', '\"') || '"'`, tbn)
+
+	return fmt.Sprintf(`CASE WHEN '%[1]s'.lang <> '' THEN %[2]s || '@' || '%[1]s'.lang WHEN '%[1]s'.datatype = '%[3]s' THEN %[2]s ELSE %[2]s || '^^<' || '%[1]s'.datatype || '>' END`,
+		tbn, value, godl.XSDNamespace+"string")
+}
+
+// rightValue returns the SQL expression of the right value of the binary
+// atom p, stored in the table tbn
+func rightValue(p predicate, tbn string) string {
+	if isDataAtom(p) {
+		return literalColumn(tbn)
+	}
+
+	return "'" + tbn + "'.rightValue"
+}
+
+// literalCondition returns the SQL condition matching the value of the data
+// atom p, stored in the table tbn, with the constant c
+func literalCondition(c string, tbn string) string {
+	if c[0] != '"' {
+		return "'" + tbn + "'.rightValue='" + strings.Replace(c, "'", "''", -1) + "' AND "
+	}
+
+	literal, err := godl.ParseLiteral(c, godl.NewNamespaces())
+	if err != nil {
+		log.Fatal("Fatal error: ", err)
+	}
+
+	quote := func(s string) string { return "'" + strings.Replace(s, "'", "''", -1) + "'" }
+
+	return "'" + tbn + "'.rightValue=" + quote(literal.Value) + " AND '" +
+		tbn + "'.datatype=" + quote(literal.Datatype) + " AND '" +
+		tbn + "'.lang=" + quote(literal.Lang) + " AND "
+}
+
 func tableName(p predicate) string {
 	var name string
 
-	if p.arity == 2 && p.nbUnderscore == 1 {
+	if isDataAtom(p) && p.args[0] == "_" {
+		// data values have no table of their own
+		name = p.name
+	} else if p.arity == 2 && p.nbUnderscore == 1 {
 		if p.args[0] == "_" {
-			name = p.name + "__GoDL_RIGHT__"
+			name = p.name + godl.RightSuffix
 		} else {
-			name = p.name + "__GoDL_LEFT__"
+			name = p.name + godl.LeftSuffix
 		}
 	} else {
 		name = p.name
@@ -153,7 +203,18 @@ func wherePart(p predicate, i int) string {
 		varName2 = varName2[1:len(varName2)]
 
 		wp += varName1 + "='" + tbn + "'.leftValue AND "
-		wp += varName2 + "='" + tbn + "'.rightValue AND "
+		wp += varName2 + "=" + rightValue(p, tbn) + " AND "
+
+	case isDataAtom(p) && p.nbUnderscore == 0 && p.nbVariables == 1 && p.args[0][0] == '?':
+		varName := p.args[0]
+		varName = varName[1:len(varName)]
+		wp += varName + "='" + tbn + "'.leftValue AND "
+		wp += literalCondition(p.args[1], tbn)
+
+	case isDataAtom(p) && p.nbUnderscore == 1 && p.args[0] == "_":
+		varName := p.args[1]
+		varName = varName[1:len(varName)]
+		wp += varName + "=" + literalColumn(tbn) + " AND "
 
 	case p.arity == 2 && p.nbUnderscore == 0 && p.nbVariables == 1:
 		name1 := p.args[0]
@@ -166,7 +227,11 @@ func wherePart(p predicate, i int) string {
 			name2 = name2[1:len(name2)]
 		}
 		wp += name1 + "='" + tbn + "'.leftValue AND "
-		wp += name2 + "='" + tbn + "'.rightValue AND "
+		if p.args[1][0] == '?' {
+			wp += name2 + "=" + rightValue(p, tbn) + " AND "
+		} else {
+			wp += name2 + "='" + tbn + "'.rightValue AND "
+		}
 
 	case p.arity == 2 && p.nbUnderscore == 1 && p.nbVariables == 1:
 		if p.args[0] != "_" {
@@ -204,16 +269,18 @@ func buildQuery(head predicate, queue []predicate, variables map[string]int) str
 		varName = varName[1:len(varName)]
 
 		switch {
+		case isDataAtom(p) && p.args[0] == "_":
+			query = fmt.Sprintf("%s %s AS %s", query, literalColumn(name+fmt.Sprint(index)), varName)
 		case p.arity == 1 || (p.arity == 2 && p.nbUnderscore == 1):
 			query = fmt.Sprintf("%s '%s%d'.value AS %s", query, name, index, varName)
 		case p.arity == 2 && p.args[0][0] == '?' && p.nbVariables == 1:
 			query = fmt.Sprintf("%s '%s%d'.leftValue AS %s", query, name, index, varName)
 		case p.arity == 2 && p.args[1][0] == '?' && p.nbVariables == 1:
-			query = fmt.Sprintf("%s '%s%d'.rightValue AS %s", query, name, index, varName)
+			query = fmt.Sprintf("%s %s AS %s", query, rightValue(p, name+fmt.Sprint(index)), varName)
 		case p.nbVariables == 2 && varName == p.args[0][1:]:
 			query = fmt.Sprintf("%s '%s%d'.leftValue AS %s", query, name, index, varName)
 		case p.nbVariables == 2 && varName == p.args[1][1:]:
-			query = fmt.Sprintf("%s '%s%d'.rightValue AS %s", query, name, index, varName)
+			query = fmt.Sprintf("%s %s AS %s", query, rightValue(p, name+fmt.Sprint(index)), varName)
 		}
 
 		if i != len(head.args)-1 {
@@ -376,6 +443,27 @@ func openDB() {
 	}
 }
 
+// importDataPropertyNames reads the data properties, absent from the
+// databases of older versions
+func importDataPropertyNames() {
+	state.dataProperties = make(map[string]bool)
+
+	query := `SELECT value FROM __GoDL_JSON__ WHERE name = 'dataPropertyNames';`
+	row := state.db.QueryRow(query)
+
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		return
+	}
+
+	var names []string
+	json.Unmarshal([]byte(raw), &names)
+
+	for _, name := range names {
+		state.dataProperties[name] = true
+	}
+}
+
 func init() {
 	usr, _ := user.Current()
 	state.dirname = usr.HomeDir + "/" + "GoDL"
@@ -383,6 +471,7 @@ func init() {
 	parseFlags()
 
 	openDB()
+	importDataPropertyNames()
 	initRegexps()
 }
 
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"godl"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Version of the tool
+var Version = "v.0.5-RC1"
+
+var state struct {
+	dirname  string
+	dbname   string
+	fullname string
+	db       *sql.DB
+	format   string
+	output   string
+	roles    bool
+	relation godl.Relation
+}
+
+func parseFlags() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "godl-taxonomy\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n  godl-taxonomy [arguments] db_name\n\n")
+		fmt.Fprintf(os.Stderr, "arguments:\n")
+
+		flag.PrintDefaults()
+	}
+
+	var help bool
+	flag.BoolVar(&help, "h", false, "this message")
+
+	var version bool
+	flag.BoolVar(&version, "v", false, "version")
+
+	var list bool
+	flag.BoolVar(&list, "l", false, "list available databases")
+
+	flag.StringVar(&state.format, "f", "tree", "output format (tree: indented text, dot: Graphviz)")
+	flag.StringVar(&state.output, "o", "", "output file, the standard output by default")
+	flag.BoolVar(&state.roles, "r", false, "write the role hierarchy instead of the class one")
+
+	flag.Parse()
+
+	if help {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	if version {
+		fmt.Println("godl-taxonomy version:", Version)
+		os.Exit(0)
+	}
+
+	if list {
+		fmt.Println("\033[1mAvailable databases:\033[0m")
+		files, _ := ioutil.ReadDir(state.dirname)
+		for i, f := range files {
+			fmt.Printf("(%d) %s\t%d\n", i, f.Name(), f.Size())
+		}
+		os.Exit(0)
+	}
+
+	switch state.format {
+	case "tree", "dot":
+	default:
+		fmt.Fprintln(os.Stderr, "unknown format:", state.format)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if flag.NArg() > 0 {
+		state.dbname = flag.Arg(0)
+	} else {
+		state.dbname = "noname.sqlite3"
+	}
+	state.fullname = state.dirname + string(os.PathSeparator) + state.dbname
+}
+
+func openDB() {
+	var err error
+	state.db, err = sql.Open("sqlite3", state.fullname)
+	log.Println("opening database", "'"+state.fullname+"'...")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// importRelation reads the class relation, or the role one
+func importRelation() {
+	name := "TBox"
+	if state.roles {
+		name = "RBox"
+	}
+
+	row := state.db.QueryRow(`SELECT value FROM __GoDL_JSON__ WHERE name = ?;`, name)
+
+	var raw []byte
+	if err := row.Scan(&raw); err != nil {
+		l := log.New(os.Stderr, "", 0)
+		l.Println(name+":", err)
+		os.Exit(1)
+	}
+
+	if err := godl.DecodeRelation(raw, &state.relation); err != nil {
+		l := log.New(os.Stderr, "", 0)
+		l.Println(err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	usr, _ := user.Current()
+	state.dirname = usr.HomeDir + "/" + "GoDL"
+
+	parseFlags()
+
+	openDB()
+	importRelation()
+}
+
+func main() {
+	defer state.db.Close()
+
+	w := os.Stdout
+	if state.output != "" {
+		file, err := os.Create(state.output)
+		if err != nil {
+			l := log.New(os.Stderr, "", 0)
+			l.Println(err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	var err error
+	if state.format == "dot" {
+		err = state.relation.WriteDOT(w)
+	} else {
+		err = state.relation.WriteTree(w)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,215 @@
+package godl
+
+// Change : an entry of the incidence matrix set by AddSubClassOf or
+// AddDisjoint, Left ⊑ Right, or Left and Right disjoint
+type Change struct {
+	Left     int
+	Right    int
+	Disjoint bool
+}
+
+// ConflictError : an axiom that would make an element both subsumed by
+// and disjoint from another one
+type ConflictError struct {
+	Left  string
+	Right string
+}
+
+func (e *ConflictError) Error() string {
+	return "'" + e.Left + "' would be both subsumed by and disjoint from '" + e.Right + "'"
+}
+
+// prepareIncremental computes the relation if it never was, and makes the
+// elements added since reflexive
+func (r *Relation) prepareIncremental() {
+	if r.compactPositive == nil {
+		r.ComputeAll()
+		return
+	}
+
+	subsumees := r.subsumeeRows()
+
+	for x := len(r.Weights); x < r.Size; x++ {
+		r.positive[x].set(x)
+		subsumees[x].set(x)
+		r.EquivalentClasses = append(r.EquivalentClasses, []int{x})
+		r.Weights = append(r.Weights, 1)
+	}
+}
+
+// updateCompactRows computes again the rows of the compact matrix given by
+// rows, the equivalent classes being unchanged
+func (r *Relation) updateCompactRows(rows bitset) {
+	subsumees := r.subsumeeRows()
+	others := r.nonRepresentatives()
+	strict := make(map[int]bitset)
+
+	strictOf := func(k int) bitset {
+		if s, ok := strict[k]; ok {
+			return s
+		}
+
+		s := r.positive[k].copy()
+		s.andNot(subsumees[k])
+		strict[k] = s
+
+		return s
+	}
+
+	rows.each(func(i int) {
+		r.compactRow(i, strictOf, others)
+	})
+}
+
+// AddSubClassOf adds subsumee ⊑ subsumer to a computed relation, updating
+// its closure, its equivalent classes and its compact matrix, and returns
+// the entries of the incidence matrix it sets. Disjointness is reported in
+// both directions. The relation is left unchanged on *ConflictError.
+func (r *Relation) AddSubClassOf(subsumee string, subsumer string) ([]Change, error) {
+	i, err := r.index(subsumee)
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := r.index(subsumer)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.AddSubClassOfIndex(i, j)
+}
+
+// AddSubClassOfIndex is AddSubClassOf, index version
+func (r *Relation) AddSubClassOfIndex(subsumee int, subsumer int) ([]Change, error) {
+	r.prepareIncremental()
+
+	if r.positive[subsumee].get(subsumer) {
+		return nil, nil
+	}
+
+	subsumees := r.subsumeeRows()
+	below := subsumees[subsumee].copy()
+	above := r.positive[subsumer]
+	disjoint := r.negative[subsumer]
+
+	conflict := -1
+	below.each(func(a int) {
+		if conflict < 0 && (above.intersects(r.negative[a]) || disjoint.intersects(r.positive[a]) || above.intersects(disjoint)) {
+			conflict = a
+		}
+	})
+
+	if conflict >= 0 {
+		return nil, &ConflictError{Left: r.Elements[conflict], Right: r.Elements[subsumer]}
+	}
+
+	changes := make([]Change, 0)
+	r.assert(subsumee, subsumer, false)
+
+	below.each(func(a int) {
+		added := above.copy()
+		added.andNot(r.positive[a])
+		added.each(func(b int) {
+			changes = append(changes, Change{Left: a, Right: b})
+			subsumees[b].set(a)
+		})
+		r.positive[a].or(above)
+
+		added = disjoint.copy()
+		added.andNot(r.negative[a])
+		added.each(func(b int) {
+			changes = append(changes, Change{Left: a, Right: b, Disjoint: true}, Change{Left: b, Right: a, Disjoint: true})
+			r.negative[b].set(a)
+		})
+		r.negative[a].or(disjoint)
+	})
+
+	if r.positive[subsumer].get(subsumee) {
+		// equivalent classes merge
+		r.ComputeEquivalentClasses()
+		r.ComputeWeights()
+		r.SortEquivalentClasses()
+		r.ComputeCompactIncidenceMatrix()
+
+		return changes, nil
+	}
+
+	below.each(func(a int) {
+		r.Weights[a] = r.positive[a].count()
+	})
+	r.SortEquivalentClasses()
+
+	// the rows whose subsumers or disjoint elements changed, and their
+	// subsumees which are already among them
+	rows := below.copy()
+	rows.or(disjoint)
+	r.updateCompactRows(rows)
+
+	return changes, nil
+}
+
+// AddDisjoint makes class1 and class2 disjoint in a computed relation,
+// updating its closure and its compact matrix, and returns the entries of
+// the incidence matrix it sets, in both directions. The relation is left
+// unchanged on *ConflictError.
+func (r *Relation) AddDisjoint(class1 string, class2 string) ([]Change, error) {
+	i, err := r.index(class1)
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := r.index(class2)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.AddDisjointIndex(i, j)
+}
+
+// AddDisjointIndex is AddDisjoint, index version
+func (r *Relation) AddDisjointIndex(class1 int, class2 int) ([]Change, error) {
+	r.prepareIncremental()
+
+	if r.negative[class1].get(class2) {
+		return nil, nil
+	}
+
+	subsumees := r.subsumeeRows()
+	below1 := subsumees[class1].copy()
+	below2 := subsumees[class2].copy()
+
+	if below1.intersects(below2) {
+		both := below1.copy()
+		for w := range both {
+			both[w] &= below2[w]
+		}
+
+		conflict := -1
+		both.each(func(a int) {
+			if conflict < 0 {
+				conflict = a
+			}
+		})
+
+		return nil, &ConflictError{Left: r.Elements[conflict], Right: r.Elements[class2]}
+	}
+
+	changes := make([]Change, 0)
+	r.assert(class1, class2, true)
+
+	below1.each(func(a int) {
+		added := below2.copy()
+		added.andNot(r.negative[a])
+		added.each(func(b int) {
+			changes = append(changes, Change{Left: a, Right: b, Disjoint: true}, Change{Left: b, Right: a, Disjoint: true})
+			r.negative[b].set(a)
+		})
+		r.negative[a].or(below2)
+	})
+
+	rows := below1
+	rows.or(below2)
+	r.updateCompactRows(rows)
+
+	return changes, nil
+}
@@ -0,0 +1,151 @@
+package godl
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// sortedClasses returns the equivalent classes of r in a canonical order
+func sortedClasses(r *Relation) []string {
+	res := make([]string, len(r.EquivalentClasses))
+	for i, eqClass := range r.EquivalentClasses {
+		res[i] = fmt.Sprint(eqClass)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+func TestIncrementalMatchesComputeAll(t *testing.T) {
+	random := rand.New(rand.NewSource(7))
+
+	for round := 0; round < 20; round++ {
+		n := 2 + random.Intn(60)
+		type axiom struct {
+			i, j     int
+			disjoint bool
+		}
+		axioms := make([]axiom, 0)
+
+		r := NewRelation(n)
+		for i := 0; i < n; i++ {
+			r.AddElement(fmt.Sprint("c", i))
+		}
+		r.ComputeAll()
+
+		for step := 0; step < 2*n; step++ {
+			a := axiom{i: random.Intn(n), j: random.Intn(n), disjoint: random.Intn(4) == 0}
+
+			before := make([][]int, n)
+			for i := range before {
+				before[i] = make([]int, n)
+				for j := range before[i] {
+					before[i][j] = r.Incidence(i, j)
+				}
+			}
+
+			var changes []Change
+			var err error
+			if a.disjoint {
+				changes, err = r.AddDisjointIndex(a.i, a.j)
+			} else {
+				changes, err = r.AddSubClassOfIndex(a.i, a.j)
+			}
+
+			if _, ok := err.(*ConflictError); ok {
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			axioms = append(axioms, a)
+
+			// the changes are exactly the entries that changed
+			changed := make(map[Change]bool)
+			for _, c := range changes {
+				if changed[c] {
+					t.Fatalf("round %d: change %v reported twice", round, c)
+				}
+				changed[c] = true
+			}
+
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					after := r.Incidence(i, j)
+					c := Change{Left: i, Right: j, Disjoint: after == -1}
+					if (after != before[i][j]) != changed[c] {
+						t.Fatalf("round %d: entry (%d, %d) went from %d to %d, changes %v", round, i, j, before[i][j], after, changes)
+					}
+				}
+			}
+		}
+
+		full := NewRelation(n)
+		for i := 0; i < n; i++ {
+			full.AddElement(fmt.Sprint("c", i))
+		}
+		for _, a := range axioms {
+			if a.disjoint {
+				full.SetDisjointClassesIndex(a.i, a.j)
+			} else {
+				full.SetSubClassOfIndex(a.i, a.j)
+			}
+		}
+		full.ComputeAll()
+
+		for i := 0; i < n; i++ {
+			if r.Weights[i] != full.Weights[i] {
+				t.Fatalf("round %d: weight of %d is %d, expected %d", round, i, r.Weights[i], full.Weights[i])
+			}
+
+			for j := 0; j < n; j++ {
+				if r.Incidence(i, j) != full.Incidence(i, j) {
+					t.Fatalf("round %d: incidence (%d, %d) is %d, expected %d", round, i, j, r.Incidence(i, j), full.Incidence(i, j))
+				}
+				if r.CompactIncidence(i, j) != full.CompactIncidence(i, j) {
+					t.Fatalf("round %d: compact incidence (%d, %d) is %d, expected %d", round, i, j, r.CompactIncidence(i, j), full.CompactIncidence(i, j))
+				}
+			}
+		}
+
+		if fmt.Sprint(sortedClasses(r)) != fmt.Sprint(sortedClasses(full)) {
+			t.Fatalf("round %d: equivalent classes %v, expected %v", round, sortedClasses(r), sortedClasses(full))
+		}
+	}
+}
+
+func TestIncrementalConflicts(t *testing.T) {
+	r := NewRelation(0)
+	r.AutoRegister = true
+
+	if _, err := r.AddSubClassOf("painter", "artist"); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := r.AddDisjoint("artist", "piece")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 4 {
+		t.Errorf("expected 4 changes, got %v", changes)
+	}
+
+	if _, err := r.AddSubClassOf("painter", "piece"); err == nil {
+		t.Error("expected a conflict")
+	} else if e, ok := err.(*ConflictError); !ok || e.Left != "painter" {
+		t.Errorf("expected a ConflictError on painter, got %v", err)
+	}
+
+	if r.Incidence(r.IndexOf["painter"], r.IndexOf["piece"]) != -1 {
+		t.Error("the relation changed on conflict")
+	}
+
+	// elements added after the closure was computed
+	if _, err := r.AddSubClassOf("cubist", "painter"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Incidence(r.IndexOf["cubist"], r.IndexOf["piece"]) != -1 || r.CompactIncidence(r.IndexOf["cubist"], r.IndexOf["painter"]) != 1 {
+		t.Error("cubist is not handled")
+	}
+}
@@ -0,0 +1,197 @@
+package godl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Well known namespaces, declared by default in every Namespaces
+const (
+	OWLNamespace  = "http://www.w3.org/2002/07/owl#"
+	RDFNamespace  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	RDFSNamespace = "http://www.w3.org/2000/01/rdf-schema#"
+	XSDNamespace  = "http://www.w3.org/2001/XMLSchema#"
+)
+
+// Namespaces : maps prefix names (without the colon) to IRIs
+type Namespaces struct {
+	Prefixes map[string]string
+}
+
+// NewNamespaces creates a Namespaces knowing the owl, rdf, rdfs and xsd prefixes
+func NewNamespaces() *Namespaces {
+	var ns Namespaces
+	ns.Prefixes = make(map[string]string)
+
+	ns.Declare("owl", OWLNamespace)
+	ns.Declare("rdf", RDFNamespace)
+	ns.Declare("rdfs", RDFSNamespace)
+	ns.Declare("xsd", XSDNamespace)
+
+	return &ns
+}
+
+// Declare binds prefix to iri
+func (ns *Namespaces) Declare(prefix string, iri string) {
+	ns.Prefixes[prefix] = iri
+}
+
+// Expand returns the canonical IRI of name: full IRIs <...> lose their
+// brackets, prefixed names ex:Class are expanded, while plain names and
+// literals are kept as they are
+func (ns *Namespaces) Expand(name string) (string, error) {
+	if len(name) > 1 && name[0] == '<' && name[len(name)-1] == '>' {
+		return name[1 : len(name)-1], nil
+	}
+
+	if len(name) == 0 || name[0] == '"' {
+		return name, nil
+	}
+
+	colon := strings.IndexByte(name, ':')
+	if colon < 0 {
+		return name, nil
+	}
+
+	prefix, local := name[:colon], name[colon+1:]
+
+	if iri, ok := ns.Prefixes[prefix]; ok {
+		return iri + local, nil
+	}
+
+	// blank nodes and unabbreviated IRIs
+	if prefix == "_" || strings.HasPrefix(local, "//") {
+		return name, nil
+	}
+
+	return name, fmt.Errorf("unknown prefix '%s:'", prefix)
+}
+
+// declarePrefix reads a Prefix(ex:=<http://...>) predicate
+func (ns *Namespaces) declarePrefix(p *DLPredicate) error {
+	n := len(p.Arguments)
+
+	if n == 0 {
+		return &ParseError{Pos: p.Span.Start, Msg: "empty Prefix declaration"}
+	}
+
+	iri := p.Arguments[n-1].Name
+	if len(iri) < 2 || iri[0] != '<' || iri[len(iri)-1] != '>' {
+		return &ParseError{Pos: p.Arguments[n-1].Span.Start, Msg: "IRI expected in Prefix declaration"}
+	}
+
+	var name string
+	for i := 0; i < n-1; i++ {
+		name += p.Arguments[i].Name
+	}
+
+	if !strings.HasSuffix(name, "=") {
+		return &ParseError{Pos: p.Span.Start, Msg: "'=' expected in Prefix declaration"}
+	}
+	name = strings.TrimSuffix(name, "=")
+	name = strings.TrimSuffix(name, ":")
+
+	ns.Declare(name, iri[1:len(iri)-1])
+
+	return nil
+}
+
+// Namespaces returns the prefixes declared by the Prefix predicates found
+// among the arguments of p (usually the result of Parse)
+func (p *DLPredicate) Namespaces() (*Namespaces, error) {
+	ns := NewNamespaces()
+
+	for i := range p.Arguments {
+		if p.Arguments[i].Name == "Prefix" {
+			if err := ns.declarePrefix(&p.Arguments[i]); err != nil {
+				return ns, err
+			}
+		}
+	}
+
+	return ns, nil
+}
+
+// Dictionary : gives each IRI a short name, usable as a table name
+type Dictionary struct {
+	ShortNames map[string]string
+	IRIs       map[string]string
+
+	// the short names in use, lower case as SQLite table names are case
+	// insensitive
+	used map[string]bool
+}
+
+// NewDictionary creates an empty Dictionary
+func NewDictionary() *Dictionary {
+	var d Dictionary
+	d.ShortNames = make(map[string]string)
+	d.IRIs = make(map[string]string)
+	d.used = make(map[string]bool)
+
+	return &d
+}
+
+// localName returns the part of iri after the last '#', '/' or ':'
+func localName(iri string) string {
+	local := iri
+	if i := strings.LastIndexAny(iri, "#/:"); i >= 0 {
+		local = iri[i+1:]
+	}
+
+	local = strings.Map(func(r rune) rune {
+		if r == '\'' || r == '"' || unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, local)
+
+	if local == "" {
+		local = "entity"
+	}
+
+	return local
+}
+
+// inUse tells if name, or the same name in another case, is the short name
+// of an IRI or of a plain name
+func (d *Dictionary) inUse(name string) bool {
+	if d.used == nil {
+		d.used = make(map[string]bool)
+		for n := range d.IRIs {
+			d.used[strings.ToLower(n)] = true
+		}
+	}
+
+	return d.used[strings.ToLower(name)]
+}
+
+// ShortName returns the short name of iri, allocating a new one the first
+// time iri is seen. Plain names are their own short names, unless another
+// entity already has it.
+func (d *Dictionary) ShortName(iri string) string {
+	if name, ok := d.ShortNames[iri]; ok {
+		return name
+	}
+
+	base := localName(iri)
+	name := base
+	for i := 2; d.inUse(name); i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+
+	d.Add(name, iri)
+
+	return name
+}
+
+// Add registers a short name for iri, as stored in a database
+func (d *Dictionary) Add(name string, iri string) {
+	d.inUse(name)
+
+	d.ShortNames[iri] = name
+	d.IRIs[name] = iri
+	d.used[strings.ToLower(name)] = true
+}
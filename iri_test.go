@@ -0,0 +1,88 @@
+package godl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamespaces(t *testing.T) {
+	s := `Prefix(:=<http://example.org/music#>)
+Prefix(ex:=<http://example.org/other/>)
+Ontology(<http://example.org/music>
+   SubClassOf(:painter <http://example.org/music#artist>)
+   SubClassOf(ex:artist owl:Thing)
+)`
+
+	result, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns, err := result.Namespaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		":painter":                          "http://example.org/music#painter",
+		"<http://example.org/music#artist>": "http://example.org/music#artist",
+		"ex:artist":                         "http://example.org/other/artist",
+		"owl:Thing":                         "http://www.w3.org/2002/07/owl#Thing",
+		"painter":                           "painter",
+	}
+
+	for name, expected := range tests {
+		if iri, err := ns.Expand(name); err != nil || iri != expected {
+			t.Errorf("Expand(%s) = %s, %v; expected %s", name, iri, err, expected)
+		}
+	}
+
+	if _, err := ns.Expand("unknown:artist"); err == nil {
+		t.Error("expected an error for an unknown prefix")
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	d := NewDictionary()
+
+	a := d.ShortName("http://example.org/music#artist")
+	b := d.ShortName("http://example.org/other/artist")
+	c := d.ShortName("http://example.org/music#artist")
+
+	if a != "artist" || a != c {
+		t.Error("bad short name:", a, c)
+	}
+
+	if b == a {
+		t.Error("two IRIs share the short name", b)
+	}
+
+	if d.IRIs[b] != "http://example.org/other/artist" {
+		t.Error("bad IRI for", b)
+	}
+}
+
+func TestDictionaryPlainNames(t *testing.T) {
+	d := NewDictionary()
+
+	names := []string{
+		d.ShortName("Person_2"),
+		d.ShortName("http://example.org/a#Person"),
+		d.ShortName("http://example.org/b#Person"),
+		d.ShortName("Person"),
+		d.ShortName("http://example.org/c#person"),
+	}
+
+	// one table per entity, SQLite table names ignoring case
+	tables := make(map[string]bool)
+	for _, name := range names {
+		if tables[strings.ToLower(name)] {
+			t.Errorf("short name %s given twice in %v", name, names)
+		}
+		tables[strings.ToLower(name)] = true
+	}
+
+	if names[0] != "Person_2" {
+		t.Error("the plain name Person_2 became", names[0])
+	}
+}
@@ -0,0 +1,287 @@
+package godl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// Position : a location in a source, lines and columns start at 1
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// String returns the position as line:column
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span : the source range [Start, End) covered by a predicate
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// String returns the start position of the span
+func (s Span) String() string {
+	return s.Start.String()
+}
+
+// ParseError : a syntax error and the position where it occurred
+type ParseError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenName
+	tokenIRI
+	tokenLiteral
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	start Position
+	end   Position
+}
+
+// lexer splits an OWL functional syntax document into tokens
+type lexer struct {
+	reader  *bufio.Reader
+	pos     Position
+	prevPos Position
+}
+
+func newLexer(r io.Reader) *lexer {
+	return &lexer{
+		reader: bufio.NewReader(r),
+		pos:    Position{Offset: 0, Line: 1, Column: 1},
+	}
+}
+
+func (l *lexer) read() (rune, error) {
+	c, size, err := l.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	l.prevPos = l.pos
+	l.pos.Offset += size
+	if c == '\n' {
+		l.pos.Line++
+		l.pos.Column = 1
+	} else {
+		l.pos.Column++
+	}
+
+	return c, nil
+}
+
+func (l *lexer) unread() {
+	l.reader.UnreadRune()
+	l.pos = l.prevPos
+}
+
+func (l *lexer) errorf(pos Position, format string, args ...interface{}) error {
+	return &ParseError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// skipBlanks skips white spaces and comments (from '#' to the end of the line)
+func (l *lexer) skipBlanks() error {
+	for {
+		c, err := l.read()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case unicode.IsSpace(c):
+		case c == '#':
+			for c != '\n' {
+				if c, err = l.read(); err != nil {
+					return err
+				}
+			}
+		default:
+			l.unread()
+			return nil
+		}
+	}
+}
+
+func isDelimiter(c rune) bool {
+	return unicode.IsSpace(c) || c == '(' || c == ')' || c == '"' || c == '<'
+}
+
+// next returns the next token of the input
+func (l *lexer) next() (token, error) {
+	if err := l.skipBlanks(); err == io.EOF {
+		return token{kind: tokenEOF, start: l.pos, end: l.pos}, nil
+	} else if err != nil {
+		return token{}, err
+	}
+
+	start := l.pos
+	c, err := l.read()
+	if err != nil {
+		return token{}, err
+	}
+
+	switch c {
+	case '(':
+		return token{kind: tokenOpen, text: "(", start: start, end: l.pos}, nil
+	case ')':
+		return token{kind: tokenClose, text: ")", start: start, end: l.pos}, nil
+	case '<':
+		return l.readIRI(start)
+	case '"':
+		return l.readLiteral(start)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteRune(c)
+
+	for {
+		c, err = l.read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return token{}, err
+		}
+
+		if isDelimiter(c) {
+			l.unread()
+			break
+		}
+		buffer.WriteRune(c)
+	}
+
+	return token{kind: tokenName, text: buffer.String(), start: start, end: l.pos}, nil
+}
+
+// readIRI reads a full IRI <...>, the opening bracket being already read
+func (l *lexer) readIRI(start Position) (token, error) {
+	var buffer bytes.Buffer
+	buffer.WriteRune('<')
+
+	for {
+		c, err := l.read()
+		if err == io.EOF || (err == nil && unicode.IsSpace(c)) {
+			return token{}, l.errorf(start, "unterminated IRI")
+		} else if err != nil {
+			return token{}, err
+		}
+
+		buffer.WriteRune(c)
+		if c == '>' {
+			return token{kind: tokenIRI, text: buffer.String(), start: start, end: l.pos}, nil
+		}
+	}
+}
+
+// readLiteral reads a quoted string, the opening quote being already read.
+// The text of the token keeps the quotes and the escape sequences.
+func (l *lexer) readLiteral(start Position) (token, error) {
+	var buffer bytes.Buffer
+	buffer.WriteRune('"')
+
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			return token{}, l.errorf(start, "unterminated string")
+		} else if err != nil {
+			return token{}, err
+		}
+
+		buffer.WriteRune(c)
+
+		switch c {
+		case '\\':
+			c, err = l.read()
+			if err == io.EOF {
+				return token{}, l.errorf(start, "unterminated string")
+			} else if err != nil {
+				return token{}, err
+			}
+			buffer.WriteRune(c)
+		case '"':
+			if err := l.readLiteralSuffix(&buffer); err != nil {
+				return token{}, err
+			}
+			return token{kind: tokenLiteral, text: buffer.String(), start: start, end: l.pos}, nil
+		}
+	}
+}
+
+// readLiteralSuffix appends to buffer the datatype ^^dt or the language tag
+// @lang following a quoted string, if any
+func (l *lexer) readLiteralSuffix(buffer *bytes.Buffer) error {
+	c, err := l.read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	switch c {
+	case '@':
+		buffer.WriteRune(c)
+	case '^':
+		pos := l.prevPos
+		if c, err = l.read(); err != nil || c != '^' {
+			return l.errorf(pos, "'^^' expected after string")
+		}
+		buffer.WriteString("^^")
+
+		if c, err = l.read(); err == nil && c == '<' {
+			iri, err := l.readIRI(l.prevPos)
+			if err != nil {
+				return err
+			}
+			buffer.WriteString(iri.text)
+			return nil
+		} else if err == nil {
+			l.unread()
+		}
+	default:
+		l.unread()
+		return nil
+	}
+
+	// language tag or prefixed datatype name
+	n := buffer.Len()
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if isDelimiter(c) {
+			l.unread()
+			break
+		}
+		buffer.WriteRune(c)
+	}
+
+	if buffer.Len() == n {
+		return l.errorf(l.pos, "datatype or language tag expected after string")
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package godl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Literal : a data value with its datatype IRI, or its language tag for
+// the rdf:langString values
+type Literal struct {
+	Value    string
+	Datatype string
+	Lang     string
+}
+
+// String returns the literal in functional syntax, "v" for the xsd:string
+// values
+func (l Literal) String() string {
+	s := QuoteString(l.Value)
+
+	switch {
+	case l.Lang != "":
+		return s + "@" + l.Lang
+	case l.Datatype == "" || l.Datatype == XSDNamespace+"string":
+		return s
+	}
+
+	return s + "^^" + entityString(l.Datatype)
+}
+
+// splitLiteral splits the literal s into its quoted value and its suffix,
+// ^^datatype or @lang
+func splitLiteral(s string) (string, string, bool) {
+	if len(s) < 2 || s[0] != '"' {
+		return s, "", false
+	}
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1], s[i+1:], true
+		}
+	}
+
+	return s, "", false
+}
+
+// ParseLiteral reads the literal s, "v", "v"@lang or "v"^^datatype, the
+// datatype being expanded with ns when ns is not nil. Plain literals are
+// xsd:string values, tagged ones rdf:langString values.
+func ParseLiteral(s string, ns *Namespaces) (Literal, error) {
+	quoted, suffix, ok := splitLiteral(s)
+	if !ok {
+		return Literal{}, fmt.Errorf("literal expected, got '%s'", s)
+	}
+
+	value, err := UnquoteString(quoted)
+	if err != nil {
+		return Literal{}, err
+	}
+
+	switch {
+	case suffix == "":
+		return Literal{Value: value, Datatype: XSDNamespace + "string"}, nil
+	case strings.HasPrefix(suffix, "@") && len(suffix) > 1:
+		return Literal{Value: value, Datatype: RDFNamespace + "langString", Lang: suffix[1:]}, nil
+	case strings.HasPrefix(suffix, "^^") && len(suffix) > 2:
+		datatype := suffix[2:]
+		if ns != nil {
+			if datatype, err = ns.Expand(datatype); err != nil {
+				return Literal{}, err
+			}
+		} else if strings.HasPrefix(datatype, "<") && strings.HasSuffix(datatype, ">") {
+			datatype = datatype[1 : len(datatype)-1]
+		}
+		return Literal{Value: value, Datatype: datatype}, nil
+	}
+
+	return Literal{}, fmt.Errorf("ill formed literal '%s'", s)
+}
@@ -0,0 +1,94 @@
+package godl
+
+import (
+	"testing"
+)
+
+func TestParseLiteral(t *testing.T) {
+	ns := NewNamespaces()
+
+	tests := []struct {
+		s        string
+		expected Literal
+		str      string
+	}{
+		{`"Ravel"`, Literal{Value: "Ravel", Datatype: XSDNamespace + "string"}, `"Ravel"`},
+		{`"Maurice \"Ravel\""@fr`, Literal{Value: `Maurice "Ravel"`, Datatype: RDFNamespace + "langString", Lang: "fr"}, `"Maurice \"Ravel\""@fr`},
+		{`"42"^^xsd:integer`, Literal{Value: "42", Datatype: XSDNamespace + "integer"}, `"42"^^<` + XSDNamespace + `integer>`},
+		{`"1875"^^<http://example.org/year>`, Literal{Value: "1875", Datatype: "http://example.org/year"}, `"1875"^^<http://example.org/year>`},
+	}
+
+	for _, test := range tests {
+		literal, err := ParseLiteral(test.s, ns)
+		if err != nil {
+			t.Errorf("%s: %s", test.s, err)
+			continue
+		}
+
+		if literal != test.expected {
+			t.Errorf("%s: expected %#v, got %#v", test.s, test.expected, literal)
+		}
+
+		if literal.String() != test.str {
+			t.Errorf("%s: expected %s, got %s", test.s, test.str, literal.String())
+		}
+	}
+
+	for _, s := range []string{`Ravel`, `"42"^^`, `"42"@`, `"42"^^foo:bar`, `"42`} {
+		if _, err := ParseLiteral(s, ns); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestDataPropertyAxioms(t *testing.T) {
+	s := `Prefix(:=<http://example.org/music#>)
+Ontology(
+   DataPropertyDomain(:age :human)
+   DataPropertyRange(:age xsd:integer)
+   DataPropertyAssertion(:age :Ravel "62"^^xsd:integer)
+   DataPropertyAssertion(rdfs:label :Ravel "Ravel"@fr)
+)`
+
+	result, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns, _ := result.Namespaces()
+	ontology := result.FindOntology()
+	axioms := make([]Axiom, len(ontology.Arguments))
+
+	for i := range ontology.Arguments {
+		if axioms[i], err = NewAxiom(&ontology.Arguments[i], ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const music = "http://example.org/music#"
+	age := DataProperty{Name: music + "age"}
+
+	if a, ok := axioms[0].(DataPropertyDomain); !ok || a.Property != age || a.Domain != (Class{Name: music + "human"}) {
+		t.Error("bad DataPropertyDomain:", axioms[0])
+	}
+
+	if a, ok := axioms[1].(DataPropertyRange); !ok || a.Range != XSDNamespace+"integer" {
+		t.Error("bad DataPropertyRange:", axioms[1])
+	}
+
+	if a, ok := axioms[2].(DataPropertyAssertion); !ok || a.Subject != music+"Ravel" || a.Value != (Literal{Value: "62", Datatype: XSDNamespace + "integer"}) {
+		t.Error("bad DataPropertyAssertion:", axioms[2])
+	}
+
+	if a, ok := axioms[3].(DataPropertyAssertion); !ok || a.Value.Lang != "fr" {
+		t.Error("bad DataPropertyAssertion:", axioms[3])
+	}
+
+	if s := result.FunctionalString(); s == "" {
+		t.Error("typed literals cannot be written back")
+	}
+
+	if _, err := Parse(`Ontology(DataPropertyAssertion(:age :Ravel "62"^))`); err == nil {
+		t.Error("expected an error for '^'")
+	}
+}
@@ -0,0 +1,207 @@
+package godl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// owlxmlEntities are the OWL/XML elements naming an entity
+var owlxmlEntities = map[string]bool{
+	"Class":              true,
+	"Datatype":           true,
+	"ObjectProperty":     true,
+	"DataProperty":       true,
+	"AnnotationProperty": true,
+	"NamedIndividual":    true,
+}
+
+// owlxmlParser maps the elements of an OWL/XML document to predicates
+type owlxmlParser struct {
+	decoder *xml.Decoder
+	base    string
+}
+
+func (p *owlxmlParser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Pos: xmlSpan(p.decoder).Start, Msg: fmt.Sprintf(format, args...)}
+}
+
+// iri returns the IRI given by the IRI or abbreviatedIRI attribute of e
+func (p *owlxmlParser) iri(e xml.StartElement) (string, error) {
+	if iri, ok := xmlAttr(e, "", "IRI"); ok {
+		return "<" + resolveIRI(p.base, iri) + ">", nil
+	}
+
+	if iri, ok := xmlAttr(e, "", "abbreviatedIRI"); ok {
+		return iri, nil
+	}
+
+	return "", p.errorf("missing IRI for '%s'", e.Name.Local)
+}
+
+// element reads the element e, found inside the element named parent
+func (p *owlxmlParser) element(e xml.StartElement, parent string) (DLPredicate, error) {
+	span := xmlSpan(p.decoder)
+	name := e.Name.Local
+
+	switch {
+	case owlxmlEntities[name]:
+		iri, err := p.iri(e)
+		if err != nil {
+			return DLPredicate{}, err
+		}
+		if err := p.decoder.Skip(); err != nil {
+			return DLPredicate{}, err
+		}
+
+		if parent == "Declaration" {
+			return newPredicate(name, span, newPredicate(iri, span)), nil
+		}
+		return newPredicate(iri, span), nil
+
+	case name == "AnonymousIndividual":
+		id, _ := xmlAttr(e, "", "nodeID")
+		if err := p.decoder.Skip(); err != nil {
+			return DLPredicate{}, err
+		}
+		return newPredicate("_:"+id, span), nil
+
+	case name == "Literal":
+		text, err := xmlText(p.decoder)
+		if err != nil {
+			return DLPredicate{}, err
+		}
+
+		literal := QuoteString(text)
+		if lang, ok := xmlAttr(e, xmlNamespace, "lang"); ok && lang != "" {
+			literal += "@" + lang
+		} else if datatype, ok := xmlAttr(e, "", "datatypeIRI"); ok && datatype != RDFNamespace+"PlainLiteral" {
+			literal += "^^<" + resolveIRI(p.base, datatype) + ">"
+		}
+		return newPredicate(literal, span), nil
+
+	case name == "IRI":
+		text, err := xmlText(p.decoder)
+		if err != nil {
+			return DLPredicate{}, err
+		}
+		return newPredicate("<"+resolveIRI(p.base, strings.TrimSpace(text))+">", span), nil
+
+	case name == "Import":
+		text, err := xmlText(p.decoder)
+		if err != nil {
+			return DLPredicate{}, err
+		}
+		return newPredicate(name, span, newPredicate("<"+resolveIRI(p.base, strings.TrimSpace(text))+">", span)), nil
+
+	case name == "AbbreviatedIRI":
+		text, err := xmlText(p.decoder)
+		if err != nil {
+			return DLPredicate{}, err
+		}
+		return newPredicate(strings.TrimSpace(text), span), nil
+	}
+
+	pred := newPredicate(name, span)
+
+	// ObjectMinCardinality(n ...)
+	if cardinality, ok := xmlAttr(e, "", "cardinality"); ok {
+		pred.Arguments = append(pred.Arguments, newPredicate(cardinality, span))
+	}
+
+	for {
+		tok, err := p.decoder.Token()
+		if err != nil {
+			return pred, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			arg, err := p.element(t, name)
+			if err != nil {
+				return pred, err
+			}
+			pred.Arguments = append(pred.Arguments, arg)
+		case xml.EndElement:
+			return pred, nil
+		}
+	}
+}
+
+// ReadOWLXML reads an OWL/XML ontology and returns the same tree as Parse
+// would for the ontology in functional syntax, Prefix declarations included
+func ReadOWLXML(r io.Reader) (DLPredicate, error) {
+	p := owlxmlParser{decoder: xml.NewDecoder(r)}
+
+	var e xml.StartElement
+	for {
+		tok, err := p.decoder.Token()
+		if err == io.EOF {
+			return DLPredicate{}, p.errorf("missing 'Ontology' element")
+		} else if err != nil {
+			return DLPredicate{}, p.errorf("%s", err)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			e = start
+			break
+		}
+	}
+
+	span := xmlSpan(p.decoder)
+	if e.Name.Local != "Ontology" {
+		return DLPredicate{}, p.errorf("'Ontology' expected, found '%s'", e.Name.Local)
+	}
+
+	root := newPredicate("", span)
+	ontology := newPredicate("Ontology", span)
+
+	iri, hasIRI := xmlAttr(e, "", "ontologyIRI")
+	p.base = xmlBase(e, "")
+	if p.base == "" && hasIRI {
+		p.base = iri
+	}
+
+	if hasIRI {
+		ontology.Arguments = append(ontology.Arguments, newPredicate("<"+iri+">", span))
+		if version, ok := xmlAttr(e, "", "versionIRI"); ok {
+			ontology.Arguments = append(ontology.Arguments, newPredicate("<"+resolveIRI(p.base, version)+">", span))
+		}
+	}
+
+	for {
+		tok, err := p.decoder.Token()
+		if err != nil {
+			return root, p.errorf("%s", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Prefix" {
+				span := xmlSpan(p.decoder)
+				name, _ := xmlAttr(t, "", "name")
+				iri, _ := xmlAttr(t, "", "IRI")
+				root.Arguments = append(root.Arguments,
+					newPredicate("Prefix", span, newPredicate(name+":=", span), newPredicate("<"+iri+">", span)))
+				if err := p.decoder.Skip(); err != nil {
+					return root, p.errorf("%s", err)
+				}
+				continue
+			}
+
+			axiom, err := p.element(t, "Ontology")
+			if err != nil {
+				if _, ok := err.(*ParseError); ok {
+					return root, err
+				}
+				return root, p.errorf("%s", err)
+			}
+			ontology.Arguments = append(ontology.Arguments, axiom)
+
+		case xml.EndElement:
+			root.Arguments = append(root.Arguments, ontology)
+			return root, nil
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package godl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const musicOWLXML = `<?xml version="1.0"?>
+<Ontology xmlns="http://www.w3.org/2002/07/owl#"
+     xml:base="http://example.org/music"
+     ontologyIRI="http://example.org/music">
+    <Prefix name="" IRI="http://example.org/music#"/>
+    <Prefix name="rdfs" IRI="http://www.w3.org/2000/01/rdf-schema#"/>
+    <Declaration>
+        <ObjectProperty IRI="#hasComposed"/>
+    </Declaration>
+    <ObjectPropertyDomain>
+        <ObjectProperty IRI="#hasComposed"/>
+        <Class IRI="#composer"/>
+    </ObjectPropertyDomain>
+    <ObjectPropertyRange>
+        <ObjectProperty abbreviatedIRI=":hasComposed"/>
+        <Class IRI="#piece"/>
+    </ObjectPropertyRange>
+    <Declaration>
+        <Class IRI="#artist"/>
+    </Declaration>
+    <Declaration>
+        <Class IRI="#composer"/>
+    </Declaration>
+    <SubClassOf>
+        <Class IRI="#composer"/>
+        <Class IRI="#artist"/>
+    </SubClassOf>
+    <SubClassOf>
+        <Annotation>
+            <AnnotationProperty abbreviatedIRI="rdfs:comment"/>
+            <Literal xml:lang="en">composers compose</Literal>
+        </Annotation>
+        <Class IRI="#composer"/>
+        <ObjectSomeValuesFrom>
+            <ObjectProperty IRI="#hasComposed"/>
+            <Class IRI="#piece"/>
+        </ObjectSomeValuesFrom>
+    </SubClassOf>
+    <Declaration>
+        <Class IRI="#piece"/>
+    </Declaration>
+    <DisjointClasses>
+        <Class IRI="#piece"/>
+        <Class IRI="#artist"/>
+    </DisjointClasses>
+    <Declaration>
+        <Class IRI="#song"/>
+    </Declaration>
+    <EquivalentClasses>
+        <Class IRI="#song"/>
+        <ObjectIntersectionOf>
+            <Class IRI="#piece"/>
+            <ObjectComplementOf>
+                <Class IRI="#artist"/>
+            </ObjectComplementOf>
+        </ObjectIntersectionOf>
+    </EquivalentClasses>
+    <ClassAssertion>
+        <Class IRI="#composer"/>
+        <NamedIndividual IRI="#ravel"/>
+    </ClassAssertion>
+    <ObjectPropertyAssertion>
+        <ObjectProperty IRI="#hasComposed"/>
+        <NamedIndividual IRI="#ravel"/>
+        <NamedIndividual IRI="#bolero"/>
+    </ObjectPropertyAssertion>
+</Ontology>
+`
+
+func TestReadOWLXML(t *testing.T) {
+	p, err := ReadOWLXML(strings.NewReader(musicOWLXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rdf, err := ReadRDFXML(strings.NewReader(musicRDFXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same axioms as the RDF/XML version, once names are expanded
+	axioms := convertAxioms(t, &p)
+	expected := convertAxioms(t, &rdf)
+
+	if len(axioms) != len(expected) {
+		t.Fatalf("expected %d axioms, got %d: %v", len(expected), len(axioms), axioms)
+	}
+
+	for i := range axioms {
+		if !reflect.DeepEqual(axioms[i], expected[i]) {
+			t.Errorf("axiom %d: expected %#v, got %#v", i, expected[i], axioms[i])
+		}
+	}
+}
+
+func TestReadOWLXMLErrors(t *testing.T) {
+	if _, err := ReadOWLXML(strings.NewReader(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"/>`)); err == nil {
+		t.Error("expected an error for a document that is not OWL/XML")
+	}
+
+	if _, err := ReadOWLXML(strings.NewReader(`<Ontology><Declaration><Class/></Declaration></Ontology>`)); err == nil {
+		t.Error("expected an error for an entity without IRI")
+	}
+}
@@ -0,0 +1,53 @@
+package godl
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minBlock : the fewest rows given to a worker, below which goroutines cost
+// more than they save
+const minBlock = 64
+
+// workers returns the number of goroutines the computations may use
+func (r *Relation) workers() int {
+	if r.Workers > 0 {
+		return r.Workers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallel calls f on 0..n-1, the rows being split in contiguous blocks
+// among the workers. f must only write to the row it is given.
+func (r *Relation) parallel(n int, f func(i int)) {
+	w := r.workers()
+	if max := n / minBlock; w > max {
+		w = max
+	}
+
+	if w <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	block := (n + w - 1) / w
+	for start := 0; start < n; start += block {
+		end := start + block
+		if end > n {
+			end = n
+		}
+
+		wg.Add(1)
+		go func(start int, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				f(i)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,58 @@
+package godl
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParallelMatchesSequential(t *testing.T) {
+	random := rand.New(rand.NewSource(25))
+
+	for round := 0; round < 10; round++ {
+		n := 1 + random.Intn(600)
+		relations := make([]*Relation, 0)
+
+		for _, workers := range []int{1, 3, 8} {
+			r := NewRelation(n)
+			r.Workers = workers
+			relations = append(relations, r)
+		}
+
+		for i := 0; i < n; i++ {
+			for _, r := range relations {
+				r.AddElement(fmt.Sprint("c", i))
+			}
+		}
+
+		// some rounds have unsatisfiable elements
+		for e := 0; e < 2*n; e++ {
+			i, j := random.Intn(n), random.Intn(n)
+			for _, r := range relations {
+				r.SetSubClassOfIndex(i, j)
+			}
+		}
+		for e := 0; e < random.Intn(n/4+1); e++ {
+			i, j := random.Intn(n), random.Intn(n)
+			for _, r := range relations {
+				r.SetDisjointClassesIndex(i, j)
+			}
+		}
+
+		reports := make([]CoherenceReport, len(relations))
+		for k, r := range relations {
+			reports[k] = r.ComputeAll()
+		}
+
+		for k, r := range relations[1:] {
+			if err := sameRelation(relations[0], r); err != nil {
+				t.Errorf("round %d, %d workers: %v", round, r.Workers, err)
+			}
+
+			if !reflect.DeepEqual(reports[0], reports[k+1]) {
+				t.Errorf("round %d, %d workers: reports differ", round, r.Workers)
+			}
+		}
+	}
+}
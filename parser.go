@@ -3,9 +3,7 @@
 package godl
 
 import (
-	"bufio"
 	"bytes"
-	"regexp"
 	"strings"
 )
 
@@ -13,52 +11,146 @@ import (
 type DLPredicate struct {
 	Name      string
 	Arguments []DLPredicate
+	Span      Span
 }
 
-func isPredicate(s string) bool {
-	return len(s) > 1 && s[len(s)-1] == '('
+// newPredicate creates the predicate name(args...) located at span
+func newPredicate(name string, span Span, args ...DLPredicate) DLPredicate {
+	if args == nil {
+		args = make([]DLPredicate, 0)
+	}
+
+	return DLPredicate{Name: name, Arguments: args, Span: span}
+}
+
+// parser builds DLPredicate trees from the tokens of a lexer
+type parser struct {
+	lex    *lexer
+	tok    token
+	peeked bool
+}
+
+func (p *parser) peek() (token, error) {
+	if !p.peeked {
+		tok, err := p.lex.next()
+		if err != nil {
+			return tok, err
+		}
+		p.tok = tok
+		p.peeked = true
+	}
+
+	return p.tok, nil
 }
 
-func preProc(s string) string {
-	re := regexp.MustCompile(`\s*\(`)
-	s = re.ReplaceAllString(s, "( ")
-	s = strings.Replace(s, ")", " ) ", -1)
+func (p *parser) next() (token, error) {
+	tok, err := p.peek()
+	p.peeked = false
 
-	return s
+	return tok, err
 }
 
-func parse(scanner *bufio.Scanner, name string) (result DLPredicate) {
-	var pred DLPredicate
+// parsePredicate parses a name, an IRI or a literal, followed by its
+// arguments when it is a keyword. Keywords are names directly followed by
+// '(', like in SubClassOf(A B); at the top level, where only keywords are
+// allowed, blanks may come before '('. Elsewhere a blank before '(' starts
+// a list: in HasKey(artist (r s) ()) the lists are arguments of HasKey.
+func (p *parser) parsePredicate(topLevel bool) (result DLPredicate, err error) {
+	tok, err := p.next()
+	if err != nil {
+		return result, err
+	}
+
+	switch tok.kind {
+	case tokenEOF:
+		return result, p.lex.errorf(tok.start, "unexpected end of input")
+	case tokenOpen:
+		return result, p.lex.errorf(tok.start, "unexpected '('")
+	case tokenClose:
+		return result, p.lex.errorf(tok.start, "unexpected ')'")
+	}
 
-	result.Name = name
+	result.Name = tok.text
 	result.Arguments = make([]DLPredicate, 0)
+	result.Span = Span{Start: tok.start, End: tok.end}
+
+	// IRIs and literals never have arguments
+	if tok.kind != tokenName {
+		return result, nil
+	}
+
+	next, err := p.peek()
+	if err != nil || next.kind != tokenOpen {
+		return result, err
+	}
+	if !topLevel && next.start.Offset != tok.end.Offset {
+		return result, nil
+	}
+	p.next()
+
+	return result, p.parseArguments(&result, tok)
+}
+
+// parseArguments parses the arguments of result up to the closing
+// parenthesis, opener being the token that starts result. Arguments that
+// are bare lists, like in HasKey(C (R S) ()), give predicates without name.
+func (p *parser) parseArguments(result *DLPredicate, opener token) error {
+	for {
+		next, err := p.peek()
+		if err != nil {
+			return err
+		}
 
-	ok := scanner.Scan()
-	token := scanner.Text()
-
-	for token != ")" && ok {
-		if isPredicate(token) {
-			pred = parse(scanner, strings.TrimRight(token, "("))
-		} else if token[0] == '"' {
-			pred.Name = token
-			for token[len(token)-1] != '"' && ok {
-				pred.Name += " "
-				ok = scanner.Scan()
-				token = scanner.Text()
-				pred.Name += token
+		switch next.kind {
+		case tokenEOF:
+			if opener.kind == tokenOpen {
+				return p.lex.errorf(opener.start, "missing ')'")
 			}
-		} else {
-			pred.Name = token
-			pred.Arguments = make([]DLPredicate, 0)
+			return p.lex.errorf(opener.start, "missing ')' for '%s'", opener.text)
+		case tokenClose:
+			p.next()
+			result.Span.End = next.end
+			return nil
+		case tokenOpen:
+			p.next()
+			list := DLPredicate{Arguments: make([]DLPredicate, 0), Span: Span{Start: next.start}}
+			if err := p.parseArguments(&list, next); err != nil {
+				return err
+			}
+			result.Arguments = append(result.Arguments, list)
+			continue
 		}
 
+		pred, err := p.parsePredicate(false)
+		if err != nil {
+			return err
+		}
 		result.Arguments = append(result.Arguments, pred)
-
-		ok = scanner.Scan()
-		token = scanner.Text()
 	}
+}
+
+// parseDocument parses predicates up to the end of the input
+func (p *parser) parseDocument() (result DLPredicate, err error) {
+	result.Arguments = make([]DLPredicate, 0)
+	result.Span.Start = p.lex.pos
 
-	return result
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return result, err
+		}
+
+		if tok.kind == tokenEOF {
+			result.Span.End = tok.end
+			return result, nil
+		}
+
+		pred, err := p.parsePredicate(true)
+		if err != nil {
+			return result, err
+		}
+		result.Arguments = append(result.Arguments, pred)
+	}
 }
 
 // FindOntology : finds the ontology predicate
@@ -111,17 +203,11 @@ func (p *DLPredicate) InfixString(depth int) string {
 	return buffer.String()
 }
 
-// Parse : parse a string formatted in ansiColorGreen
-// BUG potential preproccessing problem for strings "example (2 blanks  )"
-// TODO correct this problem ;-)
-func Parse(s string) DLPredicate {
-	s = preProc(s)
-
-	// création du scanner
-	scanner := bufio.NewScanner(strings.NewReader(s))
-	scanner.Split(bufio.ScanWords)
-
-	result := parse(scanner, "")
+// Parse : parses a string formatted in OWL functional syntax.
+// The result is an unnamed predicate whose arguments are the top level
+// predicates of s (usually Prefix and Ontology).
+func Parse(s string) (DLPredicate, error) {
+	p := parser{lex: newLexer(strings.NewReader(s))}
 
-	return result
+	return p.parseDocument()
 }
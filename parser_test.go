@@ -0,0 +1,103 @@
+package godl
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	s := `Ontology(
+   Declaration(Class(artist))
+   # a comment (with parentheses)
+   SubClassOf(painter artist)
+   AnnotationAssertion(rdfs:comment painter "a (very)  \"good\" painter")
+)`
+
+	result, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ontology := result.FindOntology()
+	if ontology == nil {
+		t.Fatal("ontology not found")
+	}
+
+	if len(ontology.Arguments) != 3 {
+		t.Fatalf("expected 3 axioms, got %d", len(ontology.Arguments))
+	}
+
+	declaration := ontology.Arguments[0]
+	if declaration.Name != "Declaration" || declaration.Arguments[0].Arguments[0].Name != "artist" {
+		t.Error("bad declaration:", declaration.InfixString(1))
+	}
+
+	sub := ontology.Arguments[1]
+	if start := sub.Span.Start; start.Line != 4 || start.Column != 4 {
+		t.Error("bad start position for SubClassOf:", start)
+	}
+	if end := sub.Span.End; end.Line != 4 || end.Column != 30 {
+		t.Error("bad end position for SubClassOf:", end)
+	}
+
+	literal := ontology.Arguments[2].Arguments[2].Name
+	if literal != `"a (very)  \"good\" painter"` {
+		t.Error("bad literal:", literal)
+	}
+}
+
+func TestParseKeywords(t *testing.T) {
+	// a blank before '(' is allowed at the top level only
+	result, err := Parse("Ontology (\n   ObjectPropertyDomain(hasComposed artist)\n)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	axiom := result.FindOntology().Arguments[0]
+	if axiom.Name != "ObjectPropertyDomain" || len(axiom.Arguments) != 2 || len(axiom.Arguments[1].Arguments) != 0 {
+		t.Error("bad axiom:", axiom.InfixString(1))
+	}
+
+	// a bare name before a list does not take it as arguments
+	result, err = Parse("HasKey(artist (r s) ())")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := result.Arguments[0]
+	if len(key.Arguments) != 3 || key.Arguments[0].Name != "artist" || len(key.Arguments[0].Arguments) != 0 ||
+		len(key.Arguments[1].Arguments) != 2 || len(key.Arguments[2].Arguments) != 0 {
+		t.Error("bad key:", key.InfixString(1))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   string
+	}{
+		{"Ontology(\n  SubClassOf(a b)\n", "1:1"},
+		{"Ontology(a))", "1:12"},
+		{"Ontology(\n  Comment(\"unterminated)\n)", "2:11"},
+		{"Ontology(<http://example.org/a b)", "1:10"},
+		{"(a b)", "1:1"},
+		{"HasKey(a (r s) (", "1:16"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		if err == nil {
+			t.Errorf("%q: expected an error", test.input)
+			continue
+		}
+
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%q: unexpected error type %T", test.input, err)
+			continue
+		}
+
+		if perr.Pos.String() != test.pos {
+			t.Errorf("%q: expected error at %s, got %s", test.input, test.pos, perr)
+		}
+	}
+}
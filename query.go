@@ -0,0 +1,172 @@
+package godl
+
+import (
+	"sort"
+)
+
+// names returns the elements of indexes, sorted
+func (r *Relation) names(indexes []int) []string {
+	res := make([]string, len(indexes))
+	for k, i := range indexes {
+		res[k] = r.Elements[i]
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// indexOf returns the index of the element s, never adding it
+func (r *Relation) indexOf(s string) (int, error) {
+	if i, ok := r.IndexOf[s]; ok {
+		return i, nil
+	}
+
+	return -1, &UnknownElementError{Name: s}
+}
+
+// selectIndexes returns the elements other than i for which keep holds
+func (r *Relation) selectIndexes(i int, keep func(int) bool) []int {
+	res := make([]int, 0)
+	for j := 0; j < r.Size; j++ {
+		if j != i && keep(j) {
+			res = append(res, j)
+		}
+	}
+
+	return res
+}
+
+// query returns the names of the elements other than name for which keep
+// holds
+func (r *Relation) query(name string, keep func(i int, j int) bool) ([]string, error) {
+	i, err := r.indexOf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.names(r.selectIndexes(i, func(j int) bool { return keep(i, j) })), nil
+}
+
+// equivalentClassOf returns the equivalent class of the element i, its
+// representative first
+func (r *Relation) equivalentClassOf(i int) []int {
+	for _, eqClass := range r.EquivalentClasses {
+		for _, index := range eqClass {
+			if index == i {
+				return eqClass
+			}
+		}
+	}
+
+	return []int{i}
+}
+
+// members returns the names of the equivalent classes of the
+// representatives reps
+func (r *Relation) members(reps []int) []string {
+	indexes := make([]int, 0, len(reps))
+	for _, rep := range reps {
+		indexes = append(indexes, r.equivalentClassOf(rep)...)
+	}
+
+	return r.names(indexes)
+}
+
+// Subsumers returns the elements above name, its equivalents included, in
+// alphabetical order like the other queries
+func (r *Relation) Subsumers(name string) ([]string, error) {
+	return r.query(name, func(i int, j int) bool { return r.Incidence(i, j) == 1 })
+}
+
+// Subsumees returns the elements below name, its equivalents included
+func (r *Relation) Subsumees(name string) ([]string, error) {
+	return r.query(name, func(i int, j int) bool { return r.Incidence(j, i) == 1 })
+}
+
+// Equivalents returns the elements equivalent to name
+func (r *Relation) Equivalents(name string) ([]string, error) {
+	return r.query(name, func(i int, j int) bool { return r.Incidence(i, j) == 1 && r.Incidence(j, i) == 1 })
+}
+
+// Disjoints returns the elements disjoint from name
+func (r *Relation) Disjoints(name string) ([]string, error) {
+	return r.query(name, func(i int, j int) bool { return r.Incidence(i, j) == -1 })
+}
+
+// DirectParents returns the elements directly above name, with their
+// equivalents, as in the Hasse diagram
+func (r *Relation) DirectParents(name string) ([]string, error) {
+	i, err := r.indexOf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := r.equivalentClassOf(i)[0]
+
+	return r.members(r.selectIndexes(rep, func(j int) bool { return r.CompactIncidence(rep, j) == 1 })), nil
+}
+
+// DirectChildren returns the elements directly below name, with their
+// equivalents, as in the Hasse diagram
+func (r *Relation) DirectChildren(name string) ([]string, error) {
+	i, err := r.indexOf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := r.equivalentClassOf(i)[0]
+
+	return r.members(r.selectIndexes(rep, func(j int) bool { return r.CompactIncidence(j, rep) == 1 })), nil
+}
+
+// IsSubClassOf tells if subsumee ⊑ subsumer
+func (r *Relation) IsSubClassOf(subsumee string, subsumer string) (bool, error) {
+	i, err := r.indexOf(subsumee)
+	if err != nil {
+		return false, err
+	}
+
+	j, err := r.indexOf(subsumer)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Incidence(i, j) == 1, nil
+}
+
+// AreDisjoint tells if class1 and class2 are disjoint
+func (r *Relation) AreDisjoint(class1 string, class2 string) (bool, error) {
+	i, err := r.indexOf(class1)
+	if err != nil {
+		return false, err
+	}
+
+	j, err := r.indexOf(class2)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Incidence(i, j) == -1, nil
+}
+
+// Roots returns the elements with no subsumer but their equivalents
+func (r *Relation) Roots() []string {
+	subsumees := r.subsumeeRows()
+
+	return r.names(r.selectIndexes(-1, func(i int) bool {
+		strict := r.positive[i].copy()
+		strict.andNot(subsumees[i])
+		return strict.count() == 0
+	}))
+}
+
+// Leaves returns the elements with no subsumee but their equivalents
+func (r *Relation) Leaves() []string {
+	subsumees := r.subsumeeRows()
+
+	return r.names(r.selectIndexes(-1, func(i int) bool {
+		strict := subsumees[i].copy()
+		strict.andNot(r.positive[i])
+		return strict.count() == 0
+	}))
+}
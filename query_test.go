@@ -0,0 +1,61 @@
+package godl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueries(t *testing.T) {
+	r := taxonomyRelation()
+
+	tests := []struct {
+		query    func(string) ([]string, error)
+		name     string
+		arg      string
+		expected []string
+	}{
+		{r.Subsumers, "Subsumers", "painter", []string{"artist", "fool", "human"}},
+		{r.Subsumees, "Subsumees", "artist", []string{"cubist", "fool", "hasComposed" + LeftSuffix, "painter"}},
+		{r.Equivalents, "Equivalents", "fool", []string{"artist"}},
+		{r.Equivalents, "Equivalents", "human", []string{}},
+		{r.Disjoints, "Disjoints", "piece", []string{"artist", "cubist", "fool", "hasComposed" + LeftSuffix, "human", "painter"}},
+		{r.DirectParents, "DirectParents", "cubist", []string{"painter"}},
+		{r.DirectParents, "DirectParents", "painter", []string{"artist", "fool"}},
+		{r.DirectParents, "DirectParents", "fool", []string{"human"}},
+		{r.DirectChildren, "DirectChildren", "fool", []string{"hasComposed" + LeftSuffix, "painter"}},
+		{r.DirectChildren, "DirectChildren", "human", []string{"artist", "fool"}},
+	}
+
+	for _, test := range tests {
+		res, err := test.query(test.arg)
+		if err != nil {
+			t.Errorf("%s(%s): %s", test.name, test.arg, err)
+		} else if !reflect.DeepEqual(res, test.expected) {
+			t.Errorf("%s(%s): expected %v, got %v", test.name, test.arg, test.expected, res)
+		}
+	}
+
+	if _, err := r.Subsumers("unknown"); err == nil {
+		t.Error("expected an error for an unknown element")
+	}
+
+	if ok, err := r.IsSubClassOf("cubist", "fool"); !ok || err != nil {
+		t.Error("cubist ⊑ fool expected", err)
+	}
+	if ok, _ := r.IsSubClassOf("fool", "cubist"); ok {
+		t.Error("fool ⊑ cubist unexpected")
+	}
+	if ok, err := r.AreDisjoint("hasComposed"+RightSuffix, "cubist"); !ok || err != nil {
+		t.Error("∃hasComposed⁻ and cubist disjoint expected", err)
+	}
+	if _, err := r.AreDisjoint("cubist", "unknown"); err == nil {
+		t.Error("expected an error for an unknown element")
+	}
+
+	if roots := r.Roots(); !reflect.DeepEqual(roots, []string{"human", "piece"}) {
+		t.Error("bad roots:", roots)
+	}
+	if leaves := r.Leaves(); !reflect.DeepEqual(leaves, []string{"cubist", "hasComposed" + LeftSuffix, "hasComposed" + RightSuffix}) {
+		t.Error("bad leaves:", leaves)
+	}
+}
@@ -0,0 +1,387 @@
+package godl
+
+import (
+	"strings"
+)
+
+func owlTerm(local string) string  { return "<" + OWLNamespace + local + ">" }
+func rdfTerm(local string) string  { return "<" + RDFNamespace + local + ">" }
+func rdfsTerm(local string) string { return "<" + RDFSNamespace + local + ">" }
+
+// characteristicAxioms maps the OWL property types to the axioms they
+// stand for, the object property axiom first
+var characteristicAxioms = map[string][2]string{
+	owlTerm("FunctionalProperty"):        {"FunctionalObjectProperty", "FunctionalDataProperty"},
+	owlTerm("InverseFunctionalProperty"): {"InverseFunctionalObjectProperty", ""},
+	owlTerm("ReflexiveProperty"):         {"ReflexiveObjectProperty", ""},
+	owlTerm("IrreflexiveProperty"):       {"IrreflexiveObjectProperty", ""},
+	owlTerm("SymmetricProperty"):         {"SymmetricObjectProperty", ""},
+	owlTerm("AsymmetricProperty"):        {"AsymmetricObjectProperty", ""},
+	owlTerm("TransitiveProperty"):        {"TransitiveObjectProperty", ""},
+}
+
+// structuralTypes are the rdf:type objects that only describe the
+// structure of the graph
+var structuralTypes = map[string]bool{
+	owlTerm("Ontology"):              true,
+	owlTerm("Restriction"):           true,
+	owlTerm("Axiom"):                 true,
+	owlTerm("AllDisjointClasses"):    true,
+	owlTerm("AllDisjointProperties"): true,
+	owlTerm("AllDifferent"):          true,
+	rdfTerm("List"):                  true,
+	rdfTerm("Property"):              true,
+}
+
+// annotationProperties are the annotation properties known without declaration
+var annotationProperties = map[string]bool{
+	rdfsTerm("label"):                 true,
+	rdfsTerm("comment"):               true,
+	rdfsTerm("seeAlso"):               true,
+	rdfsTerm("isDefinedBy"):           true,
+	owlTerm("versionInfo"):            true,
+	owlTerm("deprecated"):             true,
+	owlTerm("priorVersion"):           true,
+	owlTerm("backwardCompatibleWith"): true,
+	owlTerm("incompatibleWith"):       true,
+}
+
+// tripleGraph indexes triples by subject
+type tripleGraph struct {
+	triples   []Triple
+	bySubject map[string][]int
+}
+
+func newTripleGraph(triples []Triple) *tripleGraph {
+	g := &tripleGraph{triples: triples, bySubject: make(map[string][]int)}
+
+	for i, t := range triples {
+		g.bySubject[t.Subject] = append(g.bySubject[t.Subject], i)
+	}
+
+	return g
+}
+
+// object returns the first object of the triples s p ?o
+func (g *tripleGraph) object(s string, p string) (string, bool) {
+	for _, i := range g.bySubject[s] {
+		if g.triples[i].Predicate == p {
+			return g.triples[i].Object, true
+		}
+	}
+
+	return "", false
+}
+
+func (g *tripleGraph) hasType(s string, class string) bool {
+	for _, i := range g.bySubject[s] {
+		if g.triples[i].Predicate == rdfTerm("type") && g.triples[i].Object == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *tripleGraph) isDataProperty(s string) bool {
+	return g.hasType(s, owlTerm("DatatypeProperty"))
+}
+
+// list returns the items of the RDF list starting at head
+func (g *tripleGraph) list(head string) []string {
+	items := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for head != rdfTerm("nil") && !seen[head] {
+		seen[head] = true
+
+		first, ok := g.object(head, rdfTerm("first"))
+		if !ok {
+			break
+		}
+		items = append(items, first)
+
+		if head, ok = g.object(head, rdfTerm("rest")); !ok {
+			break
+		}
+	}
+
+	return items
+}
+
+func isBlankNode(s string) bool {
+	return strings.HasPrefix(s, "_:")
+}
+
+// propertyExpression returns the object property expression x
+func (g *tripleGraph) propertyExpression(x string, span Span) DLPredicate {
+	if isBlankNode(x) {
+		if p, ok := g.object(x, owlTerm("inverseOf")); ok {
+			return newPredicate("ObjectInverseOf", span, newPredicate(p, span))
+		}
+	}
+
+	return newPredicate(x, span)
+}
+
+func (g *tripleGraph) classExpressions(items []string, span Span) []DLPredicate {
+	args := make([]DLPredicate, len(items))
+	for i, item := range items {
+		args[i] = g.classExpression(item, span)
+	}
+
+	return args
+}
+
+// restriction returns the class expression of the owl:Restriction x
+func (g *tripleGraph) restriction(x string, span Span) (DLPredicate, bool) {
+	property, ok := g.object(x, owlTerm("onProperty"))
+	if !ok {
+		return DLPredicate{}, false
+	}
+
+	prefix := "Object"
+	p := g.propertyExpression(property, span)
+	if g.isDataProperty(property) {
+		prefix = "Data"
+	}
+
+	for _, kind := range []string{"someValuesFrom", "allValuesFrom"} {
+		if filler, ok := g.object(x, owlTerm(kind)); ok {
+			name := prefix + strings.ToUpper(kind[:1]) + kind[1:]
+			if prefix == "Data" {
+				return newPredicate(name, span, p, newPredicate(filler, span)), true
+			}
+			return newPredicate(name, span, p, g.classExpression(filler, span)), true
+		}
+	}
+
+	if value, ok := g.object(x, owlTerm("hasValue")); ok {
+		return newPredicate(prefix+"HasValue", span, p, newPredicate(value, span)), true
+	}
+
+	if _, ok := g.object(x, owlTerm("hasSelf")); ok {
+		return newPredicate("ObjectHasSelf", span, p), true
+	}
+
+	cardinalities := map[string]string{
+		"minCardinality": "MinCardinality", "minQualifiedCardinality": "MinCardinality",
+		"maxCardinality": "MaxCardinality", "maxQualifiedCardinality": "MaxCardinality",
+		"cardinality": "ExactCardinality", "qualifiedCardinality": "ExactCardinality",
+	}
+
+	for _, i := range g.bySubject[x] {
+		t := g.triples[i]
+		if !strings.HasPrefix(t.Predicate, "<"+OWLNamespace) {
+			continue
+		}
+
+		name, ok := cardinalities[t.Predicate[len(OWLNamespace)+1:len(t.Predicate)-1]]
+		if !ok {
+			continue
+		}
+
+		args := []DLPredicate{newPredicate(literalValue(t.Object), span), p}
+		if class, ok := g.object(x, owlTerm("onClass")); ok {
+			args = append(args, g.classExpression(class, span))
+		} else if dataRange, ok := g.object(x, owlTerm("onDataRange")); ok {
+			args = append(args, newPredicate(dataRange, span))
+		}
+
+		return newPredicate(prefix+name, span, args...), true
+	}
+
+	return DLPredicate{}, false
+}
+
+// classExpression returns the class expression x, a class name or a blank
+// node describing a restriction or a boolean combination of classes
+func (g *tripleGraph) classExpression(x string, span Span) DLPredicate {
+	if !isBlankNode(x) {
+		return newPredicate(x, span)
+	}
+
+	if list, ok := g.object(x, owlTerm("intersectionOf")); ok {
+		return newPredicate("ObjectIntersectionOf", span, g.classExpressions(g.list(list), span)...)
+	}
+
+	if list, ok := g.object(x, owlTerm("unionOf")); ok {
+		return newPredicate("ObjectUnionOf", span, g.classExpressions(g.list(list), span)...)
+	}
+
+	if class, ok := g.object(x, owlTerm("complementOf")); ok {
+		return newPredicate("ObjectComplementOf", span, g.classExpression(class, span))
+	}
+
+	if list, ok := g.object(x, owlTerm("oneOf")); ok {
+		individuals := g.list(list)
+		args := make([]DLPredicate, len(individuals))
+		for i, individual := range individuals {
+			args[i] = newPredicate(individual, span)
+		}
+		return newPredicate("ObjectOneOf", span, args...)
+	}
+
+	if p, ok := g.restriction(x, span); ok {
+		return p
+	}
+
+	return newPredicate(x, span)
+}
+
+// axiom returns the axiom stated by the triple t, false when t only
+// describes the structure of another axiom
+func (g *tripleGraph) axiom(t Triple, ontology string) (DLPredicate, bool) {
+	span := t.Span
+	subject := newPredicate(t.Subject, span)
+	object := newPredicate(t.Object, span)
+
+	if isBlankNode(t.Subject) {
+		if t.Predicate != rdfTerm("type") {
+			return DLPredicate{}, false
+		}
+
+		members, ok := g.object(t.Subject, owlTerm("members"))
+		if !ok {
+			return DLPredicate{}, false
+		}
+
+		switch t.Object {
+		case owlTerm("AllDisjointClasses"):
+			return newPredicate("DisjointClasses", span, g.classExpressions(g.list(members), span)...), true
+		case owlTerm("AllDisjointProperties"):
+			properties := g.list(members)
+			args := make([]DLPredicate, len(properties))
+			for i, property := range properties {
+				args[i] = g.propertyExpression(property, span)
+			}
+			if len(properties) > 0 && g.isDataProperty(properties[0]) {
+				return newPredicate("DisjointDataProperties", span, args...), true
+			}
+			return newPredicate("DisjointObjectProperties", span, args...), true
+		}
+
+		return DLPredicate{}, false
+	}
+
+	if t.Subject == ontology {
+		if t.Predicate == owlTerm("imports") {
+			return newPredicate("Import", span, object), true
+		}
+		return DLPredicate{}, false
+	}
+
+	// the object property axiom, or the data property one
+	property := func(object string, data string) string {
+		if g.isDataProperty(t.Subject) {
+			return data
+		}
+		return object
+	}
+
+	switch t.Predicate {
+	case rdfTerm("type"):
+		if kind, ok := declarationKinds[t.Object]; ok {
+			return newPredicate("Declaration", span, newPredicate(kind, span, subject)), true
+		}
+
+		if names, ok := characteristicAxioms[t.Object]; ok {
+			if g.isDataProperty(t.Subject) && names[1] != "" {
+				return newPredicate(names[1], span, subject), true
+			}
+			return newPredicate(names[0], span, g.propertyExpression(t.Subject, span)), true
+		}
+
+		if structuralTypes[t.Object] {
+			return DLPredicate{}, false
+		}
+
+		return newPredicate("ClassAssertion", span, g.classExpression(t.Object, span), subject), true
+
+	case rdfsTerm("subClassOf"):
+		return newPredicate("SubClassOf", span, g.classExpression(t.Subject, span), g.classExpression(t.Object, span)), true
+
+	case owlTerm("equivalentClass"):
+		return newPredicate("EquivalentClasses", span, g.classExpression(t.Subject, span), g.classExpression(t.Object, span)), true
+
+	case owlTerm("disjointWith"):
+		return newPredicate("DisjointClasses", span, g.classExpression(t.Subject, span), g.classExpression(t.Object, span)), true
+
+	case owlTerm("disjointUnionOf"):
+		args := append([]DLPredicate{subject}, g.classExpressions(g.list(t.Object), span)...)
+		return newPredicate("DisjointUnion", span, args...), true
+
+	case rdfsTerm("domain"):
+		if g.isDataProperty(t.Subject) {
+			return newPredicate("DataPropertyDomain", span, subject, g.classExpression(t.Object, span)), true
+		}
+		return newPredicate("ObjectPropertyDomain", span, g.propertyExpression(t.Subject, span), g.classExpression(t.Object, span)), true
+
+	case rdfsTerm("range"):
+		if g.isDataProperty(t.Subject) {
+			return newPredicate("DataPropertyRange", span, subject, object), true
+		}
+		return newPredicate("ObjectPropertyRange", span, g.propertyExpression(t.Subject, span), g.classExpression(t.Object, span)), true
+
+	case rdfsTerm("subPropertyOf"):
+		return newPredicate(property("SubObjectPropertyOf", "SubDataPropertyOf"), span,
+			g.propertyExpression(t.Subject, span), g.propertyExpression(t.Object, span)), true
+
+	case owlTerm("equivalentProperty"):
+		return newPredicate(property("EquivalentObjectProperties", "EquivalentDataProperties"), span,
+			g.propertyExpression(t.Subject, span), g.propertyExpression(t.Object, span)), true
+
+	case owlTerm("propertyDisjointWith"):
+		return newPredicate(property("DisjointObjectProperties", "DisjointDataProperties"), span,
+			g.propertyExpression(t.Subject, span), g.propertyExpression(t.Object, span)), true
+
+	case owlTerm("inverseOf"):
+		return newPredicate("InverseObjectProperties", span, subject, object), true
+	}
+
+	predicate := newPredicate(t.Predicate, span)
+
+	switch {
+	case annotationProperties[t.Predicate] || g.hasType(t.Predicate, owlTerm("AnnotationProperty")):
+		return newPredicate("AnnotationAssertion", span, predicate, subject, object), true
+	case IsLiteral(t.Object):
+		return newPredicate("DataPropertyAssertion", span, predicate, subject, object), true
+	}
+
+	return newPredicate("ObjectPropertyAssertion", span, predicate, subject, object), true
+}
+
+// NewOntologyFromTriples maps the triples of an OWL ontology in RDF to the
+// tree Parse returns for the same ontology in functional syntax. Blank nodes
+// are read as the class expressions they describe; axioms keep the order
+// of their triples.
+func NewOntologyFromTriples(triples []Triple) DLPredicate {
+	g := newTripleGraph(triples)
+	span := Span{}
+	if len(triples) > 0 {
+		span = triples[0].Span
+	}
+
+	ontology := newPredicate("Ontology", span)
+	ontologyIRI := ""
+
+	for _, t := range triples {
+		if t.Predicate == rdfTerm("type") && t.Object == owlTerm("Ontology") && !isBlankNode(t.Subject) {
+			ontologyIRI = t.Subject
+			ontology.Span = t.Span
+			ontology.Arguments = append(ontology.Arguments, newPredicate(t.Subject, t.Span))
+			if version, ok := g.object(t.Subject, owlTerm("versionIRI")); ok {
+				ontology.Arguments = append(ontology.Arguments, newPredicate(version, t.Span))
+			}
+			break
+		}
+	}
+
+	for _, t := range triples {
+		if axiom, ok := g.axiom(t, ontologyIRI); ok {
+			ontology.Arguments = append(ontology.Arguments, axiom)
+		}
+	}
+
+	return newPredicate("", span, ontology)
+}
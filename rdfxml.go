@@ -0,0 +1,358 @@
+package godl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// resolveIRI returns ref made absolute with base
+func resolveIRI(base string, ref string) string {
+	if base == "" {
+		return ref
+	}
+
+	r, err := url.Parse(ref)
+	if err != nil || r.IsAbs() {
+		return ref
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+
+	return b.ResolveReference(r).String()
+}
+
+// xmlSpan returns the current position of d
+func xmlSpan(d *xml.Decoder) Span {
+	line, column := d.InputPos()
+	pos := Position{Offset: int(d.InputOffset()), Line: line, Column: column}
+
+	return Span{Start: pos, End: pos}
+}
+
+func xmlAttr(e xml.StartElement, space string, local string) (string, bool) {
+	for _, a := range e.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// xmlBase returns the base IRI in effect inside e
+func xmlBase(e xml.StartElement, base string) string {
+	if b, ok := xmlAttr(e, xmlNamespace, "base"); ok {
+		return resolveIRI(base, b)
+	}
+
+	return base
+}
+
+// xmlText returns the text content of the element whose start tag was
+// just read, up to its end tag
+func xmlText(d *xml.Decoder) (string, error) {
+	var buffer bytes.Buffer
+
+	for depth := 1; depth > 0; {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			buffer.Write(t)
+		}
+	}
+
+	return buffer.String(), nil
+}
+
+// rdfxmlParser collects the triples of an RDF/XML document
+type rdfxmlParser struct {
+	decoder *xml.Decoder
+	triples []Triple
+	blanks  int
+}
+
+func (p *rdfxmlParser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Pos: xmlSpan(p.decoder).Start, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *rdfxmlParser) add(subject string, predicate string, object string, span Span) {
+	p.triples = append(p.triples, Triple{Subject: subject, Predicate: predicate, Object: object, Span: span})
+}
+
+func (p *rdfxmlParser) blank() string {
+	p.blanks++
+	return "_:genid" + strconv.Itoa(p.blanks)
+}
+
+func isSyntaxAttr(a xml.Attr) bool {
+	return a.Name.Space == xmlNamespace || a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") ||
+		(a.Name.Space == RDFNamespace && a.Name.Local != "type")
+}
+
+// propertyAttrs adds the triples given by the attributes of e
+func (p *rdfxmlParser) propertyAttrs(e xml.StartElement, subject string, base string, span Span) {
+	for _, a := range e.Attr {
+		if isSyntaxAttr(a) {
+			continue
+		}
+
+		if a.Name.Space == RDFNamespace {
+			p.add(subject, "<"+RDFNamespace+"type>", "<"+resolveIRI(base, a.Value)+">", span)
+		} else {
+			p.add(subject, "<"+a.Name.Space+a.Name.Local+">", QuoteString(a.Value), span)
+		}
+	}
+}
+
+// nodeElement reads the node element e and returns its subject
+func (p *rdfxmlParser) nodeElement(e xml.StartElement, base string) (string, error) {
+	base = xmlBase(e, base)
+	span := xmlSpan(p.decoder)
+
+	var subject string
+	if about, ok := xmlAttr(e, RDFNamespace, "about"); ok {
+		subject = "<" + resolveIRI(base, about) + ">"
+	} else if id, ok := xmlAttr(e, RDFNamespace, "ID"); ok {
+		subject = "<" + resolveIRI(base, "#"+id) + ">"
+	} else if id, ok := xmlAttr(e, RDFNamespace, "nodeID"); ok {
+		subject = "_:" + id
+	} else {
+		subject = p.blank()
+	}
+
+	if e.Name.Space != RDFNamespace || e.Name.Local != "Description" {
+		p.add(subject, "<"+RDFNamespace+"type>", "<"+e.Name.Space+e.Name.Local+">", span)
+	}
+
+	p.propertyAttrs(e, subject, base, span)
+
+	for {
+		tok, err := p.decoder.Token()
+		if err != nil {
+			return subject, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.propertyElement(t, subject, base); err != nil {
+				return subject, err
+			}
+		case xml.EndElement:
+			return subject, nil
+		}
+	}
+}
+
+// propertyElement reads the property element e about subject
+func (p *rdfxmlParser) propertyElement(e xml.StartElement, subject string, base string) error {
+	base = xmlBase(e, base)
+	span := xmlSpan(p.decoder)
+	predicate := "<" + e.Name.Space + e.Name.Local + ">"
+
+	if resource, ok := xmlAttr(e, RDFNamespace, "resource"); ok {
+		p.add(subject, predicate, "<"+resolveIRI(base, resource)+">", span)
+		return p.decoder.Skip()
+	}
+
+	if id, ok := xmlAttr(e, RDFNamespace, "nodeID"); ok {
+		p.add(subject, predicate, "_:"+id, span)
+		p.propertyAttrs(e, "_:"+id, base, span)
+		return p.decoder.Skip()
+	}
+
+	parseType, _ := xmlAttr(e, RDFNamespace, "parseType")
+
+	switch parseType {
+	case "Resource":
+		object := p.blank()
+		p.add(subject, predicate, object, span)
+
+		for {
+			tok, err := p.decoder.Token()
+			if err != nil {
+				return err
+			}
+
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if err := p.propertyElement(t, object, base); err != nil {
+					return err
+				}
+			case xml.EndElement:
+				return nil
+			}
+		}
+
+	case "Collection":
+		items := make([]string, 0)
+
+		for {
+			tok, err := p.decoder.Token()
+			if err != nil {
+				return err
+			}
+
+			if t, ok := tok.(xml.StartElement); ok {
+				item, err := p.nodeElement(t, base)
+				if err != nil {
+					return err
+				}
+				items = append(items, item)
+			} else if _, ok := tok.(xml.EndElement); ok {
+				break
+			}
+		}
+
+		list := "<" + RDFNamespace + "nil>"
+		for i := len(items) - 1; i >= 0; i-- {
+			cell := p.blank()
+			p.add(cell, "<"+RDFNamespace+"first>", items[i], span)
+			p.add(cell, "<"+RDFNamespace+"rest>", list, span)
+			list = cell
+		}
+		p.add(subject, predicate, list, span)
+		return nil
+
+	case "Literal":
+		text, err := xmlText(p.decoder)
+		if err != nil {
+			return err
+		}
+		p.add(subject, predicate, QuoteString(text)+"^^<"+RDFNamespace+"XMLLiteral>", span)
+		return nil
+	}
+
+	var text bytes.Buffer
+	object := ""
+
+	for {
+		tok, err := p.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if object != "" {
+				return p.errorf("more than one node in property element '%s'", e.Name.Local)
+			}
+			if object, err = p.nodeElement(t, base); err != nil {
+				return err
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if object != "" {
+				p.add(subject, predicate, object, span)
+				return nil
+			}
+
+			// empty property element with property attributes
+			for _, a := range e.Attr {
+				if !isSyntaxAttr(a) {
+					object = p.blank()
+					p.add(subject, predicate, object, span)
+					p.propertyAttrs(e, object, base, span)
+					return nil
+				}
+			}
+
+			literal := QuoteString(text.String())
+			if datatype, ok := xmlAttr(e, RDFNamespace, "datatype"); ok {
+				literal += "^^<" + resolveIRI(base, datatype) + ">"
+			} else if lang, ok := xmlAttr(e, xmlNamespace, "lang"); ok && lang != "" {
+				literal += "@" + lang
+			}
+			p.add(subject, predicate, literal, span)
+			return nil
+		}
+	}
+}
+
+// ReadRDFXMLTriples reads the triples of an RDF/XML document
+func ReadRDFXMLTriples(r io.Reader) ([]Triple, error) {
+	p := rdfxmlParser{decoder: xml.NewDecoder(r), triples: make([]Triple, 0)}
+
+	for {
+		tok, err := p.decoder.Token()
+		if err == io.EOF {
+			return p.triples, nil
+		} else if err != nil {
+			return p.triples, p.errorf("%s", err)
+		}
+
+		e, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		base := xmlBase(e, "")
+
+		if e.Name.Space != RDFNamespace || e.Name.Local != "RDF" {
+			_, err = p.nodeElement(e, base)
+		} else {
+			for err == nil {
+				if tok, err = p.decoder.Token(); err != nil {
+					break
+				}
+
+				if t, ok := tok.(xml.StartElement); ok {
+					_, err = p.nodeElement(t, base)
+				} else if _, ok := tok.(xml.EndElement); ok {
+					break
+				}
+			}
+		}
+
+		if err != nil {
+			if _, ok := err.(*ParseError); ok {
+				return p.triples, err
+			}
+			return p.triples, p.errorf("%s", err)
+		}
+	}
+}
+
+// ReadRDFXML reads an RDF/XML ontology, as saved by Protégé, and returns the
+// same tree as Parse would for the ontology in functional syntax
+func ReadRDFXML(r io.Reader) (DLPredicate, error) {
+	triples, err := ReadRDFXMLTriples(r)
+	if err != nil {
+		return DLPredicate{}, err
+	}
+
+	return NewOntologyFromTriples(triples), nil
+}
+
+// literalValue returns the value of the literal term s
+func literalValue(s string) string {
+	end := strings.LastIndexByte(s, '"')
+	if end <= 0 {
+		return s
+	}
+
+	value, err := UnquoteString(s[:end+1])
+	if err != nil {
+		return s
+	}
+
+	return value
+}
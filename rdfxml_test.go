@@ -0,0 +1,148 @@
+package godl
+
+import (
+	"strings"
+	"testing"
+)
+
+const musicRDFXML = `<?xml version="1.0"?>
+<rdf:RDF xmlns="http://example.org/music#"
+     xml:base="http://example.org/music"
+     xmlns:owl="http://www.w3.org/2002/07/owl#"
+     xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+     xmlns:rdfs="http://www.w3.org/2000/01/rdf-schema#">
+    <owl:Ontology rdf:about="http://example.org/music"/>
+    <owl:ObjectProperty rdf:about="#hasComposed">
+        <rdfs:domain rdf:resource="#composer"/>
+        <rdfs:range rdf:resource="#piece"/>
+    </owl:ObjectProperty>
+    <owl:Class rdf:about="#artist"/>
+    <owl:Class rdf:about="#composer">
+        <rdfs:subClassOf rdf:resource="#artist"/>
+        <rdfs:subClassOf>
+            <owl:Restriction>
+                <owl:onProperty rdf:resource="#hasComposed"/>
+                <owl:someValuesFrom rdf:resource="#piece"/>
+            </owl:Restriction>
+        </rdfs:subClassOf>
+    </owl:Class>
+    <owl:Class rdf:ID="piece">
+        <owl:disjointWith rdf:resource="#artist"/>
+        <rdfs:label xml:lang="en">piece</rdfs:label>
+    </owl:Class>
+    <owl:Class rdf:about="#song">
+        <owl:equivalentClass>
+            <owl:Class>
+                <owl:intersectionOf rdf:parseType="Collection">
+                    <rdf:Description rdf:about="#piece"/>
+                    <owl:Class>
+                        <owl:complementOf rdf:resource="#artist"/>
+                    </owl:Class>
+                </owl:intersectionOf>
+            </owl:Class>
+        </owl:equivalentClass>
+    </owl:Class>
+    <composer rdf:about="#ravel">
+        <hasComposed rdf:resource="#bolero"/>
+    </composer>
+</rdf:RDF>
+`
+
+// musicAxioms are the axioms of musicRDFXML in functional syntax
+var musicAxioms = []string{
+	"Declaration(ObjectProperty(<http://example.org/music#hasComposed>))",
+	"ObjectPropertyDomain(<http://example.org/music#hasComposed> <http://example.org/music#composer>)",
+	"ObjectPropertyRange(<http://example.org/music#hasComposed> <http://example.org/music#piece>)",
+	"Declaration(Class(<http://example.org/music#artist>))",
+	"Declaration(Class(<http://example.org/music#composer>))",
+	"SubClassOf(<http://example.org/music#composer> <http://example.org/music#artist>)",
+	"SubClassOf(<http://example.org/music#composer> ObjectSomeValuesFrom(<http://example.org/music#hasComposed> <http://example.org/music#piece>))",
+	"Declaration(Class(<http://example.org/music#piece>))",
+	"DisjointClasses(<http://example.org/music#piece> <http://example.org/music#artist>)",
+	"Declaration(Class(<http://example.org/music#song>))",
+	"EquivalentClasses(<http://example.org/music#song> ObjectIntersectionOf(<http://example.org/music#piece> ObjectComplementOf(<http://example.org/music#artist>)))",
+	"ClassAssertion(<http://example.org/music#composer> <http://example.org/music#ravel>)",
+	"ObjectPropertyAssertion(<http://example.org/music#hasComposed> <http://example.org/music#ravel> <http://example.org/music#bolero>)",
+}
+
+// ontologyAxioms returns the axioms of the ontology found in p, annotations
+// aside, in functional syntax
+func ontologyAxioms(t *testing.T, p *DLPredicate) []string {
+	ontology := p.FindOntology()
+	if ontology == nil {
+		t.Fatal("no ontology found")
+	}
+
+	axioms := make([]string, 0)
+	for i := range ontology.Arguments {
+		axiom := &ontology.Arguments[i]
+		if len(axiom.Arguments) > 0 && axiom.Name != "AnnotationAssertion" {
+			axioms = append(axioms, axiom.inlineString())
+		}
+	}
+
+	return axioms
+}
+
+func TestReadRDFXML(t *testing.T) {
+	p, err := ReadRDFXML(strings.NewReader(musicRDFXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ontology := p.FindOntology(); ontology.Arguments[0].Name != "<http://example.org/music>" {
+		t.Errorf("expected the ontology IRI first, got %s", ontology.Arguments[0].Name)
+	}
+
+	axioms := ontologyAxioms(t, &p)
+	if len(axioms) != len(musicAxioms) {
+		t.Fatalf("expected %d axioms, got %d:\n%s", len(musicAxioms), len(axioms), strings.Join(axioms, "\n"))
+	}
+
+	for i := range axioms {
+		if axioms[i] != musicAxioms[i] {
+			t.Errorf("axiom %d: expected %s, got %s", i, musicAxioms[i], axioms[i])
+		}
+	}
+
+	convertAxioms(t, &p)
+}
+
+// convertAxioms returns the typed axioms of the ontology found in p,
+// annotations aside
+func convertAxioms(t *testing.T, p *DLPredicate) []Axiom {
+	ns, err := p.Namespaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	axioms := make([]Axiom, 0)
+	ontology := p.FindOntology()
+
+	for i := range ontology.Arguments {
+		pred := &ontology.Arguments[i]
+		if len(pred.Arguments) == 0 || pred.Name == "AnnotationAssertion" {
+			continue
+		}
+
+		axiom, err := NewAxiom(pred, ns)
+		if err != nil {
+			t.Fatalf("%s: %s", pred.inlineString(), err)
+		}
+		axioms = append(axioms, axiom)
+	}
+
+	return axioms
+}
+
+func TestReadRDFXMLErrors(t *testing.T) {
+	s := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="http://example.org/a">
+`
+
+	if _, err := ReadRDFXML(strings.NewReader(s)); err == nil {
+		t.Error("expected an error for a truncated document")
+	} else if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected a ParseError, got %T", err)
+	}
+}
@@ -0,0 +1,86 @@
+package godl
+
+import (
+	"io"
+)
+
+// AxiomStream : a source of axioms read one at a time, like AxiomReader
+// or TurtleReader
+type AxiomStream interface {
+	Next() (DLPredicate, error)
+	Namespaces() *Namespaces
+}
+
+// AxiomReader : reads the axioms of an OWL functional syntax document one
+// at a time, without building the whole tree in memory
+type AxiomReader struct {
+	parser     parser
+	namespaces *Namespaces
+	inOntology bool
+	ontology   token
+}
+
+// NewAxiomReader creates an AxiomReader reading from r
+func NewAxiomReader(r io.Reader) *AxiomReader {
+	return &AxiomReader{
+		parser:     parser{lex: newLexer(r)},
+		namespaces: NewNamespaces(),
+	}
+}
+
+// Namespaces returns the prefixes declared so far
+func (a *AxiomReader) Namespaces() *Namespaces {
+	return a.namespaces
+}
+
+// Next returns the next top level predicate of the ontology, and io.EOF
+// when the input is exhausted. Prefix declarations are read on the way.
+func (a *AxiomReader) Next() (DLPredicate, error) {
+	p := &a.parser
+
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return DLPredicate{}, err
+		}
+
+		if a.inOntology {
+			switch tok.kind {
+			case tokenEOF:
+				return DLPredicate{}, p.lex.errorf(a.ontology.start, "missing ')' for 'Ontology'")
+			case tokenClose:
+				p.next()
+				a.inOntology = false
+				continue
+			}
+
+			return p.parsePredicate(false)
+		}
+
+		switch {
+		case tok.kind == tokenEOF:
+			return DLPredicate{}, io.EOF
+		case tok.kind == tokenName && tok.text == "Ontology":
+			p.next()
+			if open, err := p.next(); err != nil {
+				return DLPredicate{}, err
+			} else if open.kind != tokenOpen {
+				return DLPredicate{}, p.lex.errorf(open.start, "'(' expected after 'Ontology'")
+			}
+			a.inOntology = true
+			a.ontology = tok
+			continue
+		}
+
+		pred, err := p.parsePredicate(true)
+		if err != nil {
+			return pred, err
+		}
+
+		if pred.Name == "Prefix" {
+			if err := a.namespaces.declarePrefix(&pred); err != nil {
+				return pred, err
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package godl
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAxiomReader(t *testing.T) {
+	s := `Prefix(:=<http://example.org/music#>)
+Ontology(<http://example.org/music>
+   ClassAssertion(:artist :Ravel)
+   ObjectPropertyAssertion(:hasComposed :Ravel :bolero)
+)`
+
+	reader := NewAxiomReader(strings.NewReader(s))
+	names := make([]string, 0)
+
+	for {
+		axiom, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		names = append(names, axiom.Name)
+	}
+
+	if strings.Join(names, " ") != "<http://example.org/music> ClassAssertion ObjectPropertyAssertion" {
+		t.Error("unexpected axioms:", names)
+	}
+
+	if iri, _ := reader.Namespaces().Expand(":Ravel"); iri != "http://example.org/music#Ravel" {
+		t.Error("bad expansion:", iri)
+	}
+}
+
+func TestAxiomReaderError(t *testing.T) {
+	reader := NewAxiomReader(strings.NewReader("Ontology(\n  ClassAssertion(artist Ravel)\n"))
+
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reader.Next(); err == nil || err == io.EOF {
+		t.Error("expected an error for the unclosed ontology, got", err)
+	}
+}
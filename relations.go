@@ -2,23 +2,84 @@ package godl
 
 import (
 	"fmt"
-	"os"
 	"sort"
 )
-import "log"
 import "encoding/json"
 
-// Relation is a structure representing ⊑
+// Relation is a structure representing ⊑. Each element has a row of
+// subsumers and a row of disjoint elements, packed as bitsets; the
+// incidence matrices of the JSON representation are computed from them.
 type Relation struct {
-	Capacity               int
-	Size                   int
-	Elements               []string
-	IndexOf                map[string]int
+	Capacity int
+	Size     int
+	Elements []string
+	IndexOf  map[string]int
+
+	// IncidenceMatrix and CompactIncidenceMatrix are dense copies of the
+	// relation, filled by MarshalJSON and UnmarshalJSON only.
+	//
+	// Deprecated: use Incidence and CompactIncidence, which do not hold
+	// capacity² integers.
 	IncidenceMatrix        [][]int
 	CompactIncidenceMatrix [][]int
-	Weights                []int
-	EquivalentClasses      [][]int
-	Debug                  bool
+
+	Weights           []int
+	EquivalentClasses [][]int
+	Debug             bool
+
+	// AutoRegister makes SetSubClassOf and SetDisjointClasses add the
+	// names they do not know instead of failing
+	AutoRegister bool
+
+	// Source : the axiom recorded with the entries set from now on, for
+	// Explain
+	Source string
+
+	// Workers : the number of goroutines ComputeAll may use, one per CPU
+	// when 0. The results do not depend on it.
+	Workers int
+
+	positive        []bitset
+	negative        []bitset
+	compactPositive []bitset
+	compactNegative []bitset
+
+	// transposed positive rows, computed on demand
+	subsumees []bitset
+
+	// elements added by AutoRegister and not by AddElement
+	undeclared map[string]bool
+
+	// the entries as they were set, and the ones already recorded
+	assertions []Assertion
+	asserted   map[Change]bool
+}
+
+// LeftSuffix and RightSuffix name the elements standing for ∃R and ∃R⁻
+// after the property R, InverseSuffix the element of a role relation
+// standing for R⁻
+const (
+	LeftSuffix    = "__GoDL_LEFT__"
+	RightSuffix   = "__GoDL_RIGHT__"
+	InverseSuffix = "__GoDL_INVERSE__"
+)
+
+// UnknownElementError : a name that is not an element of the relation
+type UnknownElementError struct {
+	Name string
+}
+
+func (e *UnknownElementError) Error() string {
+	return "unknown element '" + e.Name + "'"
+}
+
+// UnknownPredicateError : an axiom Set cannot record
+type UnknownPredicateError struct {
+	Name string
+}
+
+func (e *UnknownPredicateError) Error() string {
+	return "unknown predicate '" + e.Name + "'"
 }
 
 // NewRelation creates a new Relation of capacity capacity
@@ -29,34 +90,130 @@ func NewRelation(capacity int) *Relation {
 	r.Elements = make([]string, 0, capacity)
 	r.IndexOf = make(map[string]int)
 	r.EquivalentClasses = make([][]int, 0)
+	r.allocate()
+
+	return &r
+}
 
-	r.IncidenceMatrix = make([][]int, capacity)
-	r.CompactIncidenceMatrix = make([][]int, capacity)
-	for i := 0; i < capacity; i++ {
-		r.IncidenceMatrix[i] = make([]int, capacity)
-		r.CompactIncidenceMatrix[i] = make([]int, capacity)
+// grow makes room for capacity elements, keeping the relation
+func (r *Relation) grow(capacity int) {
+	extend := func(rows []bitset) []bitset {
+		res := make([]bitset, capacity)
+		for i := range res {
+			res[i] = newBitset(capacity)
+			if i < len(rows) {
+				copy(res[i], rows[i])
+			}
+		}
+		return res
 	}
 
-	return &r
+	r.positive = extend(r.positive)
+	r.negative = extend(r.negative)
+	if r.compactPositive != nil {
+		r.compactPositive = extend(r.compactPositive)
+		r.compactNegative = extend(r.compactNegative)
+	}
+	if r.subsumees != nil {
+		r.subsumees = extend(r.subsumees)[:len(r.subsumees)]
+	}
+	r.Capacity = capacity
 }
 
-func (r *Relation) ComputeEquivalentClasses() {
-	marked := make([]bool, r.Size)
+func (r *Relation) allocate() {
+	r.positive = make([]bitset, r.Capacity)
+	r.negative = make([]bitset, r.Capacity)
+	for i := 0; i < r.Capacity; i++ {
+		r.positive[i] = newBitset(r.Capacity)
+		r.negative[i] = newBitset(r.Capacity)
+	}
+}
+
+// incidence returns 1 if i ⊑ j, -1 if i and j are disjoint, 0 otherwise
+func incidence(positive []bitset, negative []bitset, i int, j int) int {
+	switch {
+	case i >= len(positive) || positive[i] == nil || j/64 >= len(positive[i]):
+		return 0
+	case positive[i].get(j):
+		return 1
+	case negative[i].get(j):
+		return -1
+	}
+
+	return 0
+}
+
+// Incidence returns the entry (i, j) of the incidence matrix: 1 if i ⊑ j,
+// -1 if i and j are disjoint, 0 otherwise
+func (r *Relation) Incidence(i int, j int) int {
+	return incidence(r.positive, r.negative, i, j)
+}
+
+// CompactIncidence returns the entry (i, j) of the compact incidence
+// matrix, which only keeps the direct successors of the representatives
+// of the equivalent classes
+func (r *Relation) CompactIncidence(i int, j int) int {
+	return incidence(r.compactPositive, r.compactNegative, i, j)
+}
+
+// denseMatrix returns the capacity × capacity matrix given by entry
+func (r *Relation) denseMatrix(entry func(int, int) int) [][]int {
+	res := make([][]int, r.Capacity)
+	for i := range res {
+		res[i] = make([]int, r.Capacity)
+		for j := range res[i] {
+			res[i][j] = entry(i, j)
+		}
+	}
+
+	return res
+}
+
+// subsumeeRows returns the rows of the subsumees of each element
+func (r *Relation) subsumeeRows() []bitset {
+	if r.subsumees != nil {
+		return r.subsumees
+	}
+
+	r.subsumees = make([]bitset, r.Size)
+	for j := 0; j < r.Size; j++ {
+		r.subsumees[j] = newBitset(r.Capacity)
+	}
 
 	for i := 0; i < r.Size; i++ {
-		tmp := make([]int, 1)
+		r.positive[i].each(func(j int) {
+			r.subsumees[j].set(i)
+		})
+	}
+
+	return r.subsumees
+}
+
+func (r *Relation) ComputeEquivalentClasses() {
+	r.EquivalentClasses = make([][]int, 0)
+	marked := newBitset(r.Size)
+	subsumees := r.subsumeeRows()
 
-		if marked[i] {
+	for i := 0; i < r.Size; i++ {
+		if marked.get(i) {
 			continue
 		}
-		marked[i] = true
-		tmp[0] = i
-		for j := i + 1; j < r.Size; j++ {
-			if r.IncidenceMatrix[i][j] == 1 && r.IncidenceMatrix[i][j] == r.IncidenceMatrix[j][i] {
-				marked[j] = true
+
+		tmp := []int{i}
+		marked.set(i)
+
+		// i ⊑ j and j ⊑ i
+		equivalents := r.positive[i].copy()
+		for w := range equivalents {
+			equivalents[w] &= subsumees[i][w]
+		}
+
+		equivalents.each(func(j int) {
+			if j > i {
+				marked.set(j)
 				tmp = append(tmp, j)
 			}
-		}
+		})
 
 		r.EquivalentClasses = append(r.EquivalentClasses, tmp)
 	}
@@ -80,50 +237,79 @@ func (r *Relation) SortEquivalentClasses() {
 	sort.Sort(r)
 }
 
-// TODO: fix me ?
+// ComputeCompactIncidenceMatrix keeps the direct successors only: j is
+// dropped from the row of i when a strict subsumer of i is below j, or
+// disjoint from j. The elements that do not represent their equivalent
+// class get empty rows and columns.
 func (r *Relation) ComputeCompactIncidenceMatrix() {
-	// we remove the not direct successors
-	for i := 0; i < r.Size; i++ {
-		for j := 0; j < r.Size; j++ {
-			if val := r.IncidenceMatrix[i][j]; i != j && val != 0 {
-				for k := 0; k < r.Size; k++ {
-					if r.IncidenceMatrix[i][k] == 1 && r.IncidenceMatrix[k][j] == val &&
-						r.IncidenceMatrix[k][i] != 1 && r.IncidenceMatrix[j][k] != 1 {
-						val = 0
-						break
-					}
-				}
-				r.CompactIncidenceMatrix[i][j] = val
-			}
+	n := r.Size
+	subsumees := r.subsumeeRows()
+
+	// strict subsumers
+	strict := make([]bitset, n)
+	r.parallel(n, func(i int) {
+		strict[i] = r.positive[i].copy()
+		strict[i].andNot(subsumees[i])
+	})
+
+	r.compactPositive = make([]bitset, r.Capacity)
+	r.compactNegative = make([]bitset, r.Capacity)
+	others := r.nonRepresentatives()
+
+	r.parallel(r.Capacity, func(i int) {
+		if i < n {
+			r.compactRow(i, func(k int) bitset { return strict[k] }, others)
+		} else {
+			r.compactPositive[i] = newBitset(r.Capacity)
+			r.compactNegative[i] = newBitset(r.Capacity)
 		}
+	})
+}
 
-		// remove equivalent elements
-		for _, eqClasses := range r.EquivalentClasses {
-			for i := 1; i < len(eqClasses); i++ {
-				index := eqClasses[i]
-				for j := 0; j < r.Size; j++ {
-					r.CompactIncidenceMatrix[index][j] = 0
-					r.CompactIncidenceMatrix[j][index] = 0
-				}
-			}
+// nonRepresentatives returns the elements that do not represent their
+// equivalent class
+func (r *Relation) nonRepresentatives() bitset {
+	others := newBitset(r.Capacity)
+	for _, eqClasses := range r.EquivalentClasses {
+		for _, index := range eqClasses[1:] {
+			others.set(index)
 		}
+	}
 
+	return others
+}
+
+// compactRow computes the row i of the compact matrix, strict giving the
+// strict subsumers of the elements
+func (r *Relation) compactRow(i int, strict func(int) bitset, others bitset) {
+	positive := newBitset(r.Capacity)
+	negative := newBitset(r.Capacity)
+
+	// remove equivalent elements
+	if !others.get(i) {
+		copy(positive, r.positive[i])
+		copy(negative, r.negative[i])
+		positive.clear(i)
+		negative.clear(i)
+
+		strict(i).each(func(k int) {
+			positive.andNot(strict(k))
+			negative.andNot(r.negative[k])
+		})
+
+		positive.andNot(others)
+		negative.andNot(others)
 	}
+
+	r.compactPositive[i] = positive
+	r.compactNegative[i] = negative
 }
 
 func (r *Relation) ComputeWeights() {
 	r.Weights = make([]int, r.Size)
 
 	for i := 0; i < r.Size; i++ {
-		w := 0
-		for j := 0; j < r.Size; j++ {
-			// if r.IncidenceMatrix[i][j] != 0 {
-			if r.IncidenceMatrix[i][j] == 1 {
-				w++
-			}
-		}
-
-		r.Weights[i] = w
+		r.Weights[i] = r.positive[i].count()
 	}
 }
 
@@ -148,7 +334,7 @@ func (r *Relation) Print() {
 	for i := 0; i < r.Size; i++ {
 		fmt.Printf("%d ", i%10)
 		for j := 0; j < r.Size; j++ {
-			val := r.IncidenceMatrix[i][j]
+			val := r.Incidence(i, j)
 
 			switch val {
 			case 0:
@@ -174,7 +360,7 @@ func (r *Relation) Print() {
 	for i := 0; i < r.Size; i++ {
 		fmt.Printf("%d ", i%10)
 		for j := 0; j < r.Size; j++ {
-			val := r.CompactIncidenceMatrix[i][j]
+			val := r.CompactIncidence(i, j)
 
 			switch val {
 			case 0:
@@ -197,14 +383,69 @@ func (r *Relation) Print() {
 
 }
 
-func (r *Relation) AddElement(s string) {
+// AddElement adds the element s, growing the relation when needed, and
+// returns its index. Adding an element twice returns the first index.
+func (r *Relation) AddElement(s string) int {
+	delete(r.undeclared, s)
+
+	if i, ok := r.IndexOf[s]; ok {
+		return i
+	}
+
 	if r.Size == r.Capacity {
-		log.Fatal("Relation capacity exceeded...")
+		capacity := 2 * r.Capacity
+		if capacity < 8 {
+			capacity = 8
+		}
+		r.grow(capacity)
 	}
 
 	r.Elements = append(r.Elements, s)
 	r.IndexOf[s] = r.Size
 	r.Size++
+
+	if r.subsumees != nil {
+		r.subsumees = append(r.subsumees, newBitset(r.Capacity))
+	}
+
+	return r.Size - 1
+}
+
+// index returns the index of the element s, adding it if AutoRegister is set
+func (r *Relation) index(s string) (int, error) {
+	if i, ok := r.IndexOf[s]; ok {
+		return i, nil
+	}
+
+	if !r.AutoRegister {
+		return -1, &UnknownElementError{Name: s}
+	}
+
+	i := r.AddElement(s)
+	if r.undeclared == nil {
+		r.undeclared = make(map[string]bool)
+	}
+	r.undeclared[s] = true
+
+	if r.Debug {
+		fmt.Println("AutoRegister:", s)
+	}
+
+	return i, nil
+}
+
+// Undeclared returns the elements added by AutoRegister only, in the order
+// of their indexes
+func (r *Relation) Undeclared() []string {
+	res := make([]string, 0, len(r.undeclared))
+
+	for _, e := range r.Elements {
+		if r.undeclared[e] {
+			res = append(res, e)
+		}
+	}
+
+	return res
 }
 
 // JSON return a JSON representation of the relation
@@ -212,82 +453,243 @@ func (r *Relation) JSON() ([]byte, error) {
 	return json.Marshal(r)
 }
 
-func (r *Relation) Set(predicate string, arg1 string, arg2 string) bool {
-	i := r.IndexOf[arg1]
-	j := r.IndexOf[arg2]
+// relationJSON : the JSON representation of a Relation, with dense
+// incidence matrices
+type relationJSON struct {
+	Capacity               int
+	Size                   int
+	Elements               []string
+	IndexOf                map[string]int
+	IncidenceMatrix        [][]int
+	CompactIncidenceMatrix [][]int
+	Weights                []int
+	EquivalentClasses      [][]int
+	Debug                  bool
+	Assertions             []Assertion `json:",omitempty"`
+}
+
+// MarshalJSON writes the relation with its dense incidence matrices, which
+// are kept in IncidenceMatrix and CompactIncidenceMatrix
+func (r *Relation) MarshalJSON() ([]byte, error) {
+	r.IncidenceMatrix = r.denseMatrix(r.Incidence)
+	r.CompactIncidenceMatrix = r.denseMatrix(r.CompactIncidence)
+
+	return json.Marshal(relationJSON{
+		Capacity:               r.Capacity,
+		Size:                   r.Size,
+		Elements:               r.Elements,
+		IndexOf:                r.IndexOf,
+		IncidenceMatrix:        r.IncidenceMatrix,
+		CompactIncidenceMatrix: r.CompactIncidenceMatrix,
+		Weights:                r.Weights,
+		EquivalentClasses:      r.EquivalentClasses,
+		Debug:                  r.Debug,
+		Assertions:             r.assertions,
+	})
+}
+
+// UnmarshalJSON reads a relation written by MarshalJSON
+func (r *Relation) UnmarshalJSON(data []byte) error {
+	var v relationJSON
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*r = Relation{
+		Capacity:               v.Capacity,
+		Size:                   v.Size,
+		Elements:               v.Elements,
+		IndexOf:                v.IndexOf,
+		IncidenceMatrix:        v.IncidenceMatrix,
+		CompactIncidenceMatrix: v.CompactIncidenceMatrix,
+		Weights:                v.Weights,
+		EquivalentClasses:      v.EquivalentClasses,
+		Debug:                  v.Debug,
+	}
+
+	if r.Capacity < r.Size {
+		r.Capacity = r.Size
+	}
+	if r.IndexOf == nil {
+		r.IndexOf = make(map[string]int)
+	}
+	if r.EquivalentClasses == nil {
+		r.EquivalentClasses = make([][]int, 0)
+	}
+
+	r.allocate()
+	r.compactPositive = make([]bitset, r.Capacity)
+	r.compactNegative = make([]bitset, r.Capacity)
+	for i := 0; i < r.Capacity; i++ {
+		r.compactPositive[i] = newBitset(r.Capacity)
+		r.compactNegative[i] = newBitset(r.Capacity)
+	}
+
+	read := func(m [][]int, positive []bitset, negative []bitset) {
+		for i := 0; i < len(m) && i < r.Capacity; i++ {
+			for j := 0; j < len(m[i]) && j < r.Capacity; j++ {
+				switch m[i][j] {
+				case 1:
+					positive[i].set(j)
+				case -1:
+					negative[i].set(j)
+				}
+			}
+		}
+	}
+
+	read(v.IncidenceMatrix, r.positive, r.negative)
+	read(v.CompactIncidenceMatrix, r.compactPositive, r.compactNegative)
+
+	for _, a := range v.Assertions {
+		i, ok1 := r.IndexOf[a.Left]
+		j, ok2 := r.IndexOf[a.Right]
+		if ok1 && ok2 {
+			r.Source = a.Axiom
+			r.assert(i, j, a.Disjoint)
+		}
+	}
+	r.Source = ""
+
+	return nil
+}
+
+// Set sets the relation for the axiom predicate(arg1 arg2), one of
+// SubClassOf, DisjointClasses, EquivalentClasses, ObjectPropertyDomain,
+// ObjectPropertyRange and DataPropertyDomain, the properties standing for
+// their elements ∃R and ∃R⁻, named with LeftSuffix and RightSuffix. It
+// returns false as the setters do, and fails with an
+// *UnknownPredicateError for other predicates and an *UnknownElementError
+// for unknown names, unless AutoRegister is set.
+func (r *Relation) Set(predicate string, arg1 string, arg2 string) (bool, error) {
+	switch predicate {
+	case "ObjectPropertyDomain", "DataPropertyDomain":
+		arg1 += LeftSuffix
+	case "ObjectPropertyRange":
+		arg1 += RightSuffix
+	case "SubClassOf", "DisjointClasses", "EquivalentClasses":
+	default:
+		return false, &UnknownPredicateError{Name: predicate}
+	}
+
+	i, err := r.index(arg1)
+	if err != nil {
+		return false, err
+	}
+
+	j, err := r.index(arg2)
+	if err != nil {
+		return false, err
+	}
 
 	return r.SetIndex(predicate, i, j)
 }
 
-// TODO !!!!
-func (r *Relation) SetIndex(predicate string, arg1 int, arg2 int) bool {
-	// TODO...
+// SetIndex is Set, index version: the properties are given by the index
+// of their element ∃R, or ∃R⁻ for ObjectPropertyRange
+func (r *Relation) SetIndex(predicate string, arg1 int, arg2 int) (bool, error) {
+	switch predicate {
+	case "SubClassOf", "ObjectPropertyDomain", "ObjectPropertyRange", "DataPropertyDomain":
+		return r.SetSubClassOfIndex(arg1, arg2), nil
+	case "DisjointClasses":
+		return r.SetDisjointClassesIndex(arg1, arg2), nil
+	case "EquivalentClasses":
+		ok1 := r.SetSubClassOfIndex(arg1, arg2)
+		ok2 := r.SetSubClassOfIndex(arg2, arg1)
+		return ok1 && ok2, nil
+	}
 
-	return true
+	return false, &UnknownPredicateError{Name: predicate}
 }
 
-func (r *Relation) ComputeAll() {
+// ComputeAll computes the closure, the equivalent classes and the compact
+// matrix, and reports the unsatisfiable elements
+func (r *Relation) ComputeAll() CoherenceReport {
 	// transitivity
 	for i := 0; i < r.Size; i++ {
-		r.SetSubClassOf(r.Elements[i], r.Elements[i])
+		r.SetSubClassOfIndex(i, i)
 	}
 
-	r.ComputeClosure()
+	report := r.ComputeClosure()
 	r.ComputeEquivalentClasses()
 	r.ComputeWeights()
 	r.SortEquivalentClasses()
 	r.ComputeCompactIncidenceMatrix()
+
+	return report
 }
 
-// SetSubClassOf sets the relation for SetSubClassOf
-func (r *Relation) SetSubClassOf(subsumee string, subsumer string) bool {
-	i := r.IndexOf[subsumee]
-	j := r.IndexOf[subsumer]
+// SetSubClassOf sets the relation for SetSubClassOf. It fails with an
+// *UnknownElementError for unknown names, unless AutoRegister is set.
+func (r *Relation) SetSubClassOf(subsumee string, subsumer string) (bool, error) {
+	i, err := r.index(subsumee)
+	if err != nil {
+		return false, err
+	}
+
+	j, err := r.index(subsumer)
+	if err != nil {
+		return false, err
+	}
 
-	return r.SetSubClassOfIndex(i, j)
+	return r.SetSubClassOfIndex(i, j), nil
 }
 
-// SetSubClassOfIndex sets the relation for SetSubClassOf, index version
+// SetSubClassOfIndex sets the relation for SetSubClassOf, index version. It
+// returns false when subsumee and subsumer are set disjoint, the entry being
+// set anyway for ComputeClosure to report the unsatisfiable elements.
 func (r *Relation) SetSubClassOfIndex(subsumee int, subsumer int) bool {
-	if r.IncidenceMatrix[subsumee][subsumer] == -1 {
-		return false
-	}
-
 	if r.Debug {
 		fmt.Println("SetSubClassOf:", r.Elements[subsumee], r.Elements[subsumer])
 	}
 
-	r.IncidenceMatrix[subsumee][subsumer] = 1
+	r.positive[subsumee].set(subsumer)
+	r.subsumees = nil
 
-	return true
+	if subsumee != subsumer {
+		r.assert(subsumee, subsumer, false)
+	}
+
+	return !r.negative[subsumee].get(subsumer)
 }
 
+// SetDisjointClassesIndex sets the relation for DisjointClasses, index
+// version. It returns false when one class is set below the other, the
+// entry being set anyway for ComputeClosure to report the unsatisfiable
+// elements.
 func (r *Relation) SetDisjointClassesIndex(class1 int, class2 int) bool {
-	if r.IncidenceMatrix[class1][class2] == 1 || r.IncidenceMatrix[class2][class1] == 1 {
-		return false
-	}
-
 	if r.Debug {
 		fmt.Println("SetDisjointClasses:", r.Elements[class1], r.Elements[class2])
 	}
 
-	r.IncidenceMatrix[class1][class2] = -1
-	r.IncidenceMatrix[class2][class1] = -1
+	r.negative[class1].set(class2)
+	r.negative[class2].set(class1)
+	r.assert(class1, class2, true)
 
-	return true
+	return !r.positive[class1].get(class2) && !r.positive[class2].get(class1)
 }
 
-func (r *Relation) SetDisjointClasses(class1 string, class2 string) bool {
-	i := r.IndexOf[class1]
-	j := r.IndexOf[class2]
+// SetDisjointClasses sets the relation for DisjointClasses. It fails with
+// an *UnknownElementError for unknown names, unless AutoRegister is set.
+func (r *Relation) SetDisjointClasses(class1 string, class2 string) (bool, error) {
+	i, err := r.index(class1)
+	if err != nil {
+		return false, err
+	}
 
-	return r.SetDisjointClassesIndex(i, j)
+	j, err := r.index(class2)
+	if err != nil {
+		return false, err
+	}
+
+	return r.SetDisjointClassesIndex(i, j), nil
 }
 
 func (r *Relation) PrintSubClassOf(n int) {
 	fmt.Print("Superclass of ", r.Elements[n], " are [ ")
 	for i := 0; i < r.Size; i++ {
-		if r.IncidenceMatrix[n][i] == 1 && n != i {
+		if r.Incidence(n, i) == 1 && n != i {
 			fmt.Print(r.Elements[i], " ")
 		}
 	}
@@ -295,7 +697,7 @@ func (r *Relation) PrintSubClassOf(n int) {
 
 	fmt.Print("Subclass of ", r.Elements[n], " are [ ")
 	for i := 0; i < r.Size; i++ {
-		if r.IncidenceMatrix[i][n] == 1 && n != i {
+		if r.Incidence(i, n) == 1 && n != i {
 			fmt.Print(r.Elements[i], " ")
 		}
 	}
@@ -305,73 +707,83 @@ func (r *Relation) PrintSubClassOf(n int) {
 func (r *Relation) PrintDisjointClassOf(n int) {
 	fmt.Print("Disjoint Class of ", r.Elements[n], " are [ ")
 	for i := 0; i < r.Size; i++ {
-		if r.IncidenceMatrix[n][i] == -1 && n != i {
+		if r.Incidence(n, i) == -1 && n != i {
 			fmt.Print(r.Elements[i], " ")
 		}
 	}
 	fmt.Println("]")
 }
 
-// ComputeClosure computes the transitive closure of the relation (adaptation of Warshall's algorithm)
-func (r *Relation) ComputeClosure() {
-	m := r.IncidenceMatrix
+// ComputeClosure computes the transitive closure of the relation (adaptation
+// of Warshall's algorithm, a whole row at a time), then makes the subsumees
+// of disjoint elements disjoint, and reports the unsatisfiable elements. The
+// rows are split among Workers goroutines.
+func (r *Relation) ComputeClosure() CoherenceReport {
 	n := r.Size
+	positive := r.positive
 
-	// transitive closure
+	// transitive closure: the row k does not change at step k, so that the
+	// other rows can be updated concurrently
 	for k := 0; k < n; k++ {
-		for i := 0; i < n; i++ {
-			for j := 0; j < n; j++ {
-				if m[i][j] == 0 && m[i][k] == 1 && m[k][j] == 1 {
-					if i == 12 { // DEBUG
-						fmt.Println("i:", i, r.Elements[i])
-						fmt.Println("k:", k, r.Elements[k])
-						fmt.Println("j:", j, r.Elements[j])
-						fmt.Println()
-					}
-
-					m[i][j] = 1
-				}
+		r.parallel(n, func(i int) {
+			if i != k && positive[i].get(k) {
+				positive[i].or(positive[k])
 			}
+		})
+	}
+
+	r.subsumees = nil
+	subsumees := r.subsumeeRows()
+
+	// negative closure: k ⊑ i, l ⊑ j and i, j disjoint give k, l disjoint
+	asserted := r.negative
+	disjoint := make([]bitset, n)
+	r.parallel(n, func(k int) {
+		disjoint[k] = newBitset(r.Capacity)
+		positive[k].each(func(i int) {
+			disjoint[k].or(asserted[i])
+		})
+	})
+
+	r.negative = make([]bitset, r.Capacity)
+	r.parallel(r.Capacity, func(k int) {
+		r.negative[k] = newBitset(r.Capacity)
+		if k < n {
+			disjoint[k].each(func(j int) {
+				r.negative[k].or(subsumees[j])
+			})
 		}
+	})
+
+	return r.coherenceReport(asserted)
+}
+
+// printConflict explains why k is both below and disjoint from an element
+func (r *Relation) printConflict(k int) {
+	conflict := r.positive[k].copy()
+	for w := range conflict {
+		conflict[w] &= r.negative[k][w]
 	}
 
-	// negative closure
-	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			if m[i][j] == -1 {
-				for k := 0; k < n; k++ {
-					if m[k][i] == 1 {
-
-						if m[k][j] == 1 { // DEBUG
-							fmt.Println("i:", i, r.Elements[i])
-							fmt.Println("j:", j, r.Elements[j])
-							fmt.Println("k:", k, r.Elements[k])
-							fmt.Println()
-
-							fmt.Println(r.Elements[k], "⊑ -", r.Elements[j])
-							fmt.Println("because...")
-							fmt.Println(r.Elements[i], "⊑ -", r.Elements[j])
-							fmt.Println(r.Elements[k], "⊑", r.Elements[i])
-							fmt.Println("but...")
-							fmt.Println(r.Elements[k], "⊑ ", r.Elements[j])
-
-							fmt.Println()
-							r.PrintSubClassOf(i)
-							r.PrintDisjointClassOf(i)
-							r.PrintSubClassOf(j)
-							r.PrintDisjointClassOf(j)
-							r.PrintSubClassOf(k)
-							r.PrintDisjointClassOf(k)
-							os.Exit(-1)
-						}
-
-						m[k][j] = -1
-						m[j][k] = -1
-					}
-				}
-			}
+	l := -1
+	conflict.each(func(j int) {
+		if l < 0 {
+			l = j
 		}
+	})
+
+	fmt.Println(r.Elements[k], "⊑ -", r.Elements[l])
+	fmt.Println("because...")
+	for _, a := range r.explainDisjoint(k, l) {
+		fmt.Println(" ", a)
 	}
+	fmt.Println("but...")
+	fmt.Println(r.Elements[k], "⊑ ", r.Elements[l])
+	fmt.Println("because...")
+	for _, a := range r.explainSubClassOf(k, l) {
+		fmt.Println(" ", a)
+	}
+	fmt.Println()
 }
 
 func myAssert(cond bool, msg string) {
@@ -1,9 +1,12 @@
 package godl
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"math/rand"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -39,7 +42,281 @@ func Test(t *testing.T) {
 
 }
 
+// denseCompact computes the closure and the compact matrix the way the
+// former [][]int implementation did
+func denseCompact(n int, m [][]int) [][]int {
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if m[i][j] == 0 && m[i][k] == 1 && m[k][j] == 1 {
+					m[i][j] = 1
+				}
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if m[i][j] == -1 {
+				for k := 0; k < n; k++ {
+					if m[k][i] == 1 {
+						m[k][j] = -1
+						m[j][k] = -1
+					}
+				}
+			}
+		}
+	}
+
+	compact := make([][]int, n)
+	for i := 0; i < n; i++ {
+		compact[i] = make([]int, n)
+		for j := 0; j < n; j++ {
+			if val := m[i][j]; i != j && val != 0 {
+				for k := 0; k < n; k++ {
+					if m[i][k] == 1 && m[k][j] == val && m[k][i] != 1 && m[j][k] != 1 {
+						val = 0
+						break
+					}
+				}
+				compact[i][j] = val
+			}
+		}
+	}
+
+	return compact
+}
+
+func TestRelationMatchesDense(t *testing.T) {
+	random := rand.New(rand.NewSource(42))
+
+	for round := 0; round < 20; round++ {
+		n := 1 + random.Intn(150)
+		r := NewRelation(n)
+		m := make([][]int, n)
+
+		for i := 0; i < n; i++ {
+			r.AddElement(fmt.Sprint("c", i))
+			m[i] = make([]int, n)
+			m[i][i] = 1
+			r.SetSubClassOfIndex(i, i)
+		}
+
+		for e := 0; e < 2*n; e++ {
+			i, j := random.Intn(n), random.Intn(n)
+			m[i][j] = 1
+			r.SetSubClassOfIndex(i, j)
+		}
+
+		// disjoint pairs without common subsumee, so that there is no conflict
+		closure := make([][]int, n)
+		for i := range m {
+			closure[i] = append([]int(nil), m[i]...)
+		}
+		denseCompact(n, closure)
+
+		for e := 0; e < n/2; e++ {
+			i, j := random.Intn(n), random.Intn(n)
+			common := false
+			for k := 0; k < n; k++ {
+				if closure[k][i] == 1 && closure[k][j] == 1 {
+					common = true
+				}
+			}
+
+			if !common && m[i][j] == 0 && m[j][i] == 0 {
+				m[i][j], m[j][i] = -1, -1
+				r.SetDisjointClassesIndex(i, j)
+			}
+		}
+
+		compact := denseCompact(n, m)
+		r.ComputeAll()
+
+		equivalents := make(map[int]bool)
+		for _, eqClass := range r.EquivalentClasses {
+			for _, index := range eqClass[1:] {
+				equivalents[index] = true
+			}
+		}
+
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if r.Incidence(i, j) != m[i][j] {
+					t.Fatalf("round %d: incidence (%d, %d) is %d, expected %d", round, i, j, r.Incidence(i, j), m[i][j])
+				}
+
+				expected := compact[i][j]
+				if equivalents[i] || equivalents[j] {
+					expected = 0
+				}
+				if r.CompactIncidence(i, j) != expected {
+					t.Fatalf("round %d: compact incidence (%d, %d) is %d, expected %d", round, i, j, r.CompactIncidence(i, j), expected)
+				}
+			}
+		}
+
+		data, err := r.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded Relation
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(decoded.EquivalentClasses, r.EquivalentClasses) || decoded.Size != n {
+			t.Fatalf("round %d: bad decoded relation", round)
+		}
+
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if decoded.Incidence(i, j) != r.Incidence(i, j) || decoded.CompactIncidence(i, j) != r.CompactIncidence(i, j) {
+					t.Fatalf("round %d: (%d, %d) differs once decoded", round, i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestRelationGrows(t *testing.T) {
+	r := NewRelation(0)
+
+	for i := 0; i < 100; i++ {
+		if index := r.AddElement(fmt.Sprint("c", i)); index != i {
+			t.Fatalf("expected index %d, got %d", i, index)
+		}
+		if i > 0 {
+			if _, err := r.SetSubClassOf(fmt.Sprint("c", i), fmt.Sprint("c", i-1)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if index := r.AddElement("c42"); index != 42 || r.Size != 100 {
+		t.Errorf("adding c42 again gave %d, size %d", index, r.Size)
+	}
+
+	if _, err := r.SetSubClassOf("c1", "unknown"); err == nil {
+		t.Error("expected an error for an unknown element")
+	} else if e, ok := err.(*UnknownElementError); !ok || e.Name != "unknown" {
+		t.Errorf("expected an UnknownElementError, got %v", err)
+	}
+
+	if _, err := r.SetDisjointClasses("unknown", "c1"); err == nil {
+		t.Error("expected an error for an unknown element")
+	}
+
+	r.AutoRegister = true
+	if ok, err := r.SetDisjointClasses("d", "c0"); !ok || err != nil {
+		t.Fatal("cannot register d:", err)
+	}
+	r.SetSubClassOf("e", "c99")
+	r.AddElement("e")
+
+	if undeclared := r.Undeclared(); !reflect.DeepEqual(undeclared, []string{"d"}) {
+		t.Errorf("expected [d] undeclared, got %v", undeclared)
+	}
+
+	r.ComputeAll()
+
+	if r.Incidence(r.IndexOf["c99"], r.IndexOf["c0"]) != 1 || r.Incidence(r.IndexOf["e"], r.IndexOf["d"]) != -1 {
+		t.Error("bad closure after growing")
+	}
+}
+
+func TestSet(t *testing.T) {
+	r := NewRelation(0)
+
+	for _, e := range []string{"artist", "human", "fool", "piece", "hasComposed" + LeftSuffix, "hasComposed" + RightSuffix} {
+		r.AddElement(e)
+	}
+
+	axioms := [][3]string{
+		{"SubClassOf", "artist", "human"},
+		{"EquivalentClasses", "artist", "fool"},
+		{"DisjointClasses", "artist", "piece"},
+		{"ObjectPropertyDomain", "hasComposed", "artist"},
+		{"ObjectPropertyRange", "hasComposed", "piece"},
+	}
+
+	for _, a := range axioms {
+		if ok, err := r.Set(a[0], a[1], a[2]); !ok || err != nil {
+			t.Fatalf("%s(%s %s): %v", a[0], a[1], a[2], err)
+		}
+	}
+
+	if _, err := r.Set("TransitiveObjectProperty", "hasComposed", "artist"); err == nil {
+		t.Error("expected an error for an unknown predicate")
+	} else if _, ok := err.(*UnknownPredicateError); !ok {
+		t.Errorf("expected an UnknownPredicateError, got %v", err)
+	}
+
+	if _, err := r.Set("DataPropertyDomain", "age", "human"); err == nil {
+		t.Error("expected an error for an unknown property")
+	}
+
+	if _, err := r.SetIndex("ClassAssertion", 0, 1); err == nil {
+		t.Error("expected an error for an unknown predicate")
+	}
+
+	r.ComputeAll()
+
+	tests := []struct {
+		left, right string
+		expected    int
+	}{
+		{"fool", "human", 1},
+		{"artist", "fool", 1},
+		{"hasComposed" + LeftSuffix, "human", 1},
+		{"hasComposed" + RightSuffix, "fool", -1},
+		{"human", "artist", 0},
+	}
+
+	for _, test := range tests {
+		if val := r.Incidence(r.IndexOf[test.left], r.IndexOf[test.right]); val != test.expected {
+			t.Errorf("(%s, %s): expected %d, got %d", test.left, test.right, test.expected, val)
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 	os.Exit(m.Run())
 }
+
+func TestIncidenceMatrixFields(t *testing.T) {
+	r := NewRelation(5)
+	for _, e := range []string{"a", "b", "c"} {
+		r.AddElement(e)
+	}
+	r.SetSubClassOf("a", "b")
+	r.SetDisjointClasses("b", "c")
+	r.ComputeAll()
+
+	data, err := r.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Relation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rel := range []*Relation{r, &decoded} {
+		m, compact := rel.IncidenceMatrix, rel.CompactIncidenceMatrix
+		if len(m) != r.Capacity || len(m[0]) != r.Capacity || len(compact) != r.Capacity {
+			t.Fatalf("expected %d × %d matrices", r.Capacity, r.Capacity)
+		}
+
+		for i := 0; i < r.Capacity; i++ {
+			for j := 0; j < r.Capacity; j++ {
+				if m[i][j] != r.Incidence(i, j) || compact[i][j] != r.CompactIncidence(i, j) {
+					t.Errorf("bad entry (%d, %d)", i, j)
+				}
+			}
+		}
+	}
+}
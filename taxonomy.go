@@ -0,0 +1,170 @@
+package godl
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DisplayName returns the element e as written in DL: ∃R and ∃R⁻ for the
+// elements standing for them, R⁻ for the inverse roles
+func DisplayName(e string) string {
+	switch {
+	case strings.HasSuffix(e, LeftSuffix):
+		return "∃" + strings.TrimSuffix(e, LeftSuffix)
+	case strings.HasSuffix(e, RightSuffix):
+		return "∃" + strings.TrimSuffix(e, RightSuffix) + "⁻"
+	case strings.HasSuffix(e, InverseSuffix):
+		return strings.TrimSuffix(e, InverseSuffix) + "⁻"
+	}
+
+	return e
+}
+
+// classLabel returns the names of the elements of eqClass, joined with ≡
+func (r *Relation) classLabel(eqClass []int) string {
+	names := make([]string, len(eqClass))
+	for k, index := range eqClass {
+		names[k] = DisplayName(r.Elements[index])
+	}
+
+	return strings.Join(names, " ≡ ")
+}
+
+// representatives returns the equivalent classes by representative
+func (r *Relation) representatives() map[int][]int {
+	res := make(map[int][]int)
+	for _, eqClass := range r.EquivalentClasses {
+		res[eqClass[0]] = eqClass
+	}
+
+	return res
+}
+
+// sortedRepresentatives returns the representatives of the equivalent
+// classes, sorted by label
+func (r *Relation) sortedRepresentatives(classes map[int][]int) []int {
+	res := make([]int, 0, len(classes))
+	for i := range classes {
+		res = append(res, i)
+	}
+
+	sort.Slice(res, func(a, b int) bool {
+		return r.classLabel(classes[res[a]]) < r.classLabel(classes[res[b]])
+	})
+
+	return res
+}
+
+// dotQuote quotes s as a DOT identifier
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// WriteDOT writes the Hasse diagram of the computed relation in the
+// Graphviz DOT language: one node per equivalent class, subsumers above,
+// disjoint classes linked by dashed edges, unsatisfiable classes in red
+func (r *Relation) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	classes := r.representatives()
+	sorted := r.sortedRepresentatives(classes)
+
+	node := func(i int) string {
+		return "n" + strconv.Itoa(i)
+	}
+
+	bw.WriteString("digraph taxonomy {\n")
+	bw.WriteString("\trankdir=BT;\n")
+	bw.WriteString("\tnode [shape=box];\n")
+
+	for _, i := range sorted {
+		bw.WriteString("\t" + node(i) + " [label=" + dotQuote(r.classLabel(classes[i])))
+		if r.Unsatisfiable(i) {
+			bw.WriteString(", color=red")
+		}
+		bw.WriteString("];\n")
+	}
+
+	for _, i := range sorted {
+		for _, j := range sorted {
+			switch r.CompactIncidence(i, j) {
+			case 1:
+				bw.WriteString("\t" + node(i) + " -> " + node(j) + ";\n")
+			case -1:
+				// once per pair, when no subsumer of either class is
+				// disjoint from the other
+				if i < j && r.CompactIncidence(j, i) == -1 {
+					bw.WriteString("\t" + node(i) + " -> " + node(j) + " [style=dashed, dir=none, constraint=false];\n")
+				}
+			}
+		}
+	}
+
+	bw.WriteString("}\n")
+
+	return bw.Flush()
+}
+
+// WriteTree writes the computed relation as an indented tree, the
+// subsumees of each class below it. A class with several direct subsumers
+// is written under each of them, its subsumees under the first one only.
+func (r *Relation) WriteTree(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	classes := r.representatives()
+	sorted := r.sortedRepresentatives(classes)
+	written := make(map[int]bool)
+
+	// the direct subsumees of each class, read once from the compact
+	// matrix, and whether it has a direct subsumer
+	children := make(map[int][]int)
+	top := make(map[int]bool)
+	for _, i := range sorted {
+		top[i] = true
+	}
+	for _, k := range sorted {
+		for _, i := range sorted {
+			if k != i && r.CompactIncidence(k, i) == 1 {
+				children[i] = append(children[i], k)
+				top[k] = false
+			}
+		}
+	}
+
+	var write func(i int, prefix string, last bool, root bool)
+	write = func(i int, prefix string, last bool, root bool) {
+		branch, next := "├── ", "│   "
+		if last {
+			branch, next = "└── ", "    "
+		}
+		if root {
+			branch, next = "", ""
+		}
+
+		label := r.classLabel(classes[i])
+		if r.Unsatisfiable(i) {
+			label += " (unsatisfiable)"
+		}
+
+		sub := children[i]
+		if written[i] && len(sub) > 0 {
+			bw.WriteString(prefix + branch + label + " …\n")
+			return
+		}
+		written[i] = true
+		bw.WriteString(prefix + branch + label + "\n")
+
+		for k, child := range sub {
+			write(child, prefix+next, k == len(sub)-1, false)
+		}
+	}
+
+	for _, i := range sorted {
+		if top[i] {
+			write(i, "", true, true)
+		}
+	}
+
+	return bw.Flush()
+}
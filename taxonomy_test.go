@@ -0,0 +1,80 @@
+package godl
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func taxonomyRelation() *Relation {
+	r := NewRelation(0)
+	r.AutoRegister = true
+
+	r.SetSubClassOf("artist", "human")
+	r.SetSubClassOf("painter", "artist")
+	r.SetSubClassOf("artist", "fool")
+	r.SetSubClassOf("fool", "artist")
+	r.SetSubClassOf("cubist", "painter")
+	r.SetSubClassOf("hasComposed"+LeftSuffix, "artist")
+	r.SetSubClassOf("hasComposed"+RightSuffix, "piece")
+	r.SetDisjointClasses("human", "piece")
+	r.ComputeAll()
+
+	return r
+}
+
+func TestWriteTree(t *testing.T) {
+	var buffer bytes.Buffer
+
+	if err := taxonomyRelation().WriteTree(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `human
+└── artist ≡ fool
+    ├── painter
+    │   └── cubist
+    └── ∃hasComposed
+piece
+└── ∃hasComposed⁻
+`
+
+	if buffer.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buffer.String())
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buffer bytes.Buffer
+	r := taxonomyRelation()
+
+	if err := r.WriteDOT(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	dot := buffer.String()
+	node := func(name string) string {
+		return "n" + strconv.Itoa(r.IndexOf[name])
+	}
+
+	for _, line := range []string{
+		node("artist") + ` [label="artist ≡ fool"];`,
+		node("cubist") + " -> " + node("painter") + ";",
+		node("painter") + " -> " + node("artist") + ";",
+		node("artist") + " -> " + node("human") + ";",
+		node("human") + " -> " + node("piece") + " [style=dashed",
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("%s not found in\n%s", line, dot)
+		}
+	}
+
+	if strings.Contains(dot, node("fool")+" [") || strings.Contains(dot, node("cubist")+" -> "+node("human")) {
+		t.Errorf("the diagram is not reduced:\n%s", dot)
+	}
+
+	if n := strings.Count(dot, "dashed"); n != 1 {
+		t.Errorf("expected 1 disjointness edge, got %d", n)
+	}
+}
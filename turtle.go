@@ -0,0 +1,677 @@
+package godl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Triple : an RDF statement. Terms are written as in functional syntax:
+// <iri>, _:label for blank nodes, and "value", "value"@lang or
+// "value"^^<datatype> for literals.
+type Triple struct {
+	Subject   string
+	Predicate string
+	Object    string
+	Span      Span
+}
+
+// IsLiteral tells if the term s is a literal
+func IsLiteral(s string) bool {
+	return len(s) > 0 && s[0] == '"'
+}
+
+// TurtleReader : reads the triples of a Turtle or N-Triples document one
+// statement at a time
+type TurtleReader struct {
+	reader     *bufio.Reader
+	pos        Position
+	namespaces *Namespaces
+	base       *url.URL
+	pending    []Triple
+	blanks     int
+	start      Position
+}
+
+// NewTurtleReader creates a TurtleReader reading from r
+func NewTurtleReader(r io.Reader) *TurtleReader {
+	return &TurtleReader{
+		reader:     bufio.NewReader(r),
+		pos:        Position{Offset: 0, Line: 1, Column: 1},
+		namespaces: NewNamespaces(),
+	}
+}
+
+// NewNTriplesReader creates a reader for N-Triples, which is a subset of Turtle
+func NewNTriplesReader(r io.Reader) *TurtleReader {
+	return NewTurtleReader(r)
+}
+
+// Namespaces returns the prefixes declared so far
+func (t *TurtleReader) Namespaces() *Namespaces {
+	return t.namespaces
+}
+
+func (t *TurtleReader) errorf(format string, args ...interface{}) error {
+	return &ParseError{Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (t *TurtleReader) peek() (rune, error) {
+	c, _, err := t.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	t.reader.UnreadRune()
+
+	return c, nil
+}
+
+func (t *TurtleReader) read() (rune, error) {
+	c, size, err := t.reader.ReadRune()
+	if err == io.EOF {
+		return 0, t.errorf("unexpected end of input")
+	} else if err != nil {
+		return 0, err
+	}
+
+	t.pos.Offset += size
+	if c == '\n' {
+		t.pos.Line++
+		t.pos.Column = 1
+	} else {
+		t.pos.Column++
+	}
+
+	return c, nil
+}
+
+func (t *TurtleReader) expect(c rune) error {
+	if err := t.skipBlanks(); err == io.EOF {
+		return t.errorf("'%c' expected", c)
+	} else if err != nil {
+		return err
+	}
+
+	got, err := t.read()
+	if err != nil {
+		return err
+	}
+
+	if got != c {
+		return t.errorf("'%c' expected, got '%c'", c, got)
+	}
+
+	return nil
+}
+
+// skipBlanks skips white spaces and comments, io.EOF being returned at the
+// end of the input
+func (t *TurtleReader) skipBlanks() error {
+	for {
+		c, err := t.peek()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case unicode.IsSpace(c):
+			t.read()
+		case c == '#':
+			for c != '\n' {
+				if c, err = t.peek(); err != nil {
+					return err
+				}
+				t.read()
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func isTurtleDelimiter(c rune) bool {
+	return unicode.IsSpace(c) || strings.ContainsRune("<>\"'[](){};,#", c)
+}
+
+// readWord reads a prefixed name or a keyword. A dot ends the word unless
+// it is followed by a name character.
+func (t *TurtleReader) readWord() (string, error) {
+	var buffer bytes.Buffer
+
+	for {
+		c, err := t.peek()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		if isTurtleDelimiter(c) {
+			break
+		}
+
+		if c == '.' {
+			next, _ := t.reader.Peek(2)
+			if len(next) < 2 || isTurtleDelimiter(rune(next[1])) || next[1] == '.' {
+				break
+			}
+		}
+
+		t.read()
+		buffer.WriteRune(c)
+	}
+
+	if buffer.Len() == 0 {
+		return "", t.errorf("name expected")
+	}
+
+	return buffer.String(), nil
+}
+
+func (t *TurtleReader) readEscape() (rune, error) {
+	c, err := t.read()
+	if err != nil {
+		return 0, err
+	}
+
+	switch c {
+	case 't':
+		return '\t', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+
+		digits := make([]rune, n)
+		for i := range digits {
+			if digits[i], err = t.read(); err != nil {
+				return 0, err
+			}
+		}
+
+		code, err := strconv.ParseUint(string(digits), 16, 32)
+		if err != nil {
+			return 0, t.errorf("bad escape sequence \\%c%s", c, string(digits))
+		}
+		return rune(code), nil
+	}
+
+	return c, nil
+}
+
+// resolve returns iri made absolute with the base IRI
+func (t *TurtleReader) resolve(iri string) string {
+	if t.base == nil {
+		return iri
+	}
+
+	ref, err := url.Parse(iri)
+	if err != nil || ref.IsAbs() {
+		return iri
+	}
+
+	return t.base.ResolveReference(ref).String()
+}
+
+// readIRI reads <...> and returns the absolute IRI without brackets
+func (t *TurtleReader) readIRI() (string, error) {
+	if err := t.expect('<'); err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+
+	for {
+		c, err := t.read()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case c == '>':
+			return t.resolve(buffer.String()), nil
+		case c == '\\':
+			if c, err = t.readEscape(); err != nil {
+				return "", err
+			}
+		case unicode.IsSpace(c):
+			return "", t.errorf("unterminated IRI")
+		}
+
+		buffer.WriteRune(c)
+	}
+}
+
+// readString reads a short or long quoted string and returns its value
+func (t *TurtleReader) readString() (string, error) {
+	quote, err := t.read()
+	if err != nil {
+		return "", err
+	}
+
+	long := false
+	if next, _ := t.reader.Peek(2); len(next) == 2 && rune(next[0]) == quote && rune(next[1]) == quote {
+		t.read()
+		t.read()
+		long = true
+	}
+
+	var buffer bytes.Buffer
+
+	for {
+		c, err := t.read()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case c == '\\':
+			if c, err = t.readEscape(); err != nil {
+				return "", err
+			}
+		case c == quote && !long:
+			return buffer.String(), nil
+		case c == quote:
+			if next, _ := t.reader.Peek(2); len(next) == 2 && rune(next[0]) == quote && rune(next[1]) == quote {
+				t.read()
+				t.read()
+				return buffer.String(), nil
+			}
+		case c == '\n' && !long:
+			return "", t.errorf("unterminated string")
+		}
+
+		buffer.WriteRune(c)
+	}
+}
+
+// readLiteral reads a string and its language tag or datatype
+func (t *TurtleReader) readLiteral() (string, error) {
+	value, err := t.readString()
+	if err != nil {
+		return "", err
+	}
+
+	literal := QuoteString(value)
+
+	c, err := t.peek()
+	if err == io.EOF {
+		return literal, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	switch c {
+	case '@':
+		t.read()
+		lang, err := t.readWord()
+		if err != nil {
+			return "", err
+		}
+		literal += "@" + lang
+
+	case '^':
+		t.read()
+		if err := t.expect('^'); err != nil {
+			return "", err
+		}
+
+		datatype, err := t.readName()
+		if err != nil {
+			return "", err
+		}
+		literal += "^^" + datatype
+	}
+
+	return literal, nil
+}
+
+// readName reads an IRI or a prefixed name and returns <iri>
+func (t *TurtleReader) readName() (string, error) {
+	if c, err := t.peek(); err == nil && c == '<' {
+		iri, err := t.readIRI()
+		return "<" + iri + ">", err
+	}
+
+	word, err := t.readWord()
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.ContainsRune(word, ':') {
+		return "", t.errorf("prefixed name expected, got '%s'", word)
+	}
+
+	iri, err := t.namespaces.Expand(word)
+	if err != nil {
+		return "", t.errorf("%s", err)
+	}
+
+	return "<" + iri + ">", nil
+}
+
+// readTerm reads a subject or an object
+func (t *TurtleReader) readTerm() (string, error) {
+	if err := t.skipBlanks(); err != nil {
+		if err == io.EOF {
+			return "", t.errorf("unexpected end of input")
+		}
+		return "", err
+	}
+
+	c, _ := t.peek()
+
+	switch {
+	case c == '<':
+		return t.readName()
+	case c == '"' || c == '\'':
+		return t.readLiteral()
+	case c == '[':
+		return t.readBlankNode()
+	case c == '(':
+		return "", t.errorf("collections not supported")
+	case c == '_':
+		word, err := t.readWord()
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(word, "_:") {
+			return "", t.errorf("blank node expected, got '%s'", word)
+		}
+		return word, nil
+	case c == '+' || c == '-' || c == '.' || unicode.IsDigit(c):
+		word, err := t.readWord()
+		if err != nil {
+			return "", err
+		}
+
+		datatype := "integer"
+		switch {
+		case strings.ContainsAny(word, "eE"):
+			datatype = "double"
+		case strings.ContainsRune(word, '.'):
+			datatype = "decimal"
+		}
+
+		return QuoteString(word) + "^^<" + XSDNamespace + datatype + ">", nil
+	}
+
+	word, err := t.readWord()
+	if err != nil {
+		return "", err
+	}
+
+	switch word {
+	case "true", "false":
+		return QuoteString(word) + "^^<" + XSDNamespace + "boolean>", nil
+	}
+
+	iri, err := t.namespaces.Expand(word)
+	if err != nil {
+		return "", t.errorf("%s", err)
+	}
+	if !strings.ContainsRune(word, ':') {
+		return "", t.errorf("unexpected '%s'", word)
+	}
+
+	return "<" + iri + ">", nil
+}
+
+// readBlankNode reads [ predicate object ... ] and returns a fresh blank node
+func (t *TurtleReader) readBlankNode() (string, error) {
+	t.read()
+	t.blanks++
+	node := "_:genid" + strconv.Itoa(t.blanks)
+
+	if err := t.skipBlanks(); err != nil {
+		return "", err
+	}
+
+	if c, _ := t.peek(); c == ']' {
+		t.read()
+		return node, nil
+	}
+
+	if err := t.readPredicateObjectList(node); err != nil {
+		return "", err
+	}
+
+	return node, t.expect(']')
+}
+
+func (t *TurtleReader) readPredicate() (string, error) {
+	if err := t.skipBlanks(); err != nil {
+		return "", err
+	}
+
+	if c, _ := t.peek(); c == 'a' {
+		if next, _ := t.reader.Peek(2); len(next) == 2 && isTurtleDelimiter(rune(next[1])) {
+			t.read()
+			return "<" + RDFNamespace + "type>", nil
+		}
+	}
+
+	return t.readName()
+}
+
+// readPredicateObjectList reads p1 o1, o2 ; p2 o3 ... about subject
+func (t *TurtleReader) readPredicateObjectList(subject string) error {
+	for {
+		predicate, err := t.readPredicate()
+		if err != nil {
+			return err
+		}
+
+		for {
+			object, err := t.readTerm()
+			if err != nil {
+				return err
+			}
+
+			t.pending = append(t.pending, Triple{
+				Subject:   subject,
+				Predicate: predicate,
+				Object:    object,
+				Span:      Span{Start: t.start, End: t.pos},
+			})
+
+			if err := t.skipBlanks(); err != nil && err != io.EOF {
+				return err
+			}
+			if c, _ := t.peek(); c != ',' {
+				break
+			}
+			t.read()
+		}
+
+		if err := t.skipBlanks(); err != nil && err != io.EOF {
+			return err
+		}
+		if c, _ := t.peek(); c != ';' {
+			return nil
+		}
+
+		// repeated and trailing semicolons
+		for c, _ := t.peek(); c == ';'; c, _ = t.peek() {
+			t.read()
+			if err := t.skipBlanks(); err != nil && err != io.EOF {
+				return err
+			}
+		}
+		if c, _ := t.peek(); c == '.' || c == ']' {
+			return nil
+		}
+	}
+}
+
+// readDirective reads @prefix, @base, PREFIX and BASE
+func (t *TurtleReader) readDirective(keyword string, sparql bool) error {
+	switch strings.ToLower(keyword) {
+	case "prefix":
+		if err := t.skipBlanks(); err != nil {
+			return err
+		}
+
+		name, err := t.readWord()
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(name, ":") {
+			return t.errorf("prefix name expected, got '%s'", name)
+		}
+
+		iri, err := t.readIRI()
+		if err != nil {
+			return err
+		}
+		t.namespaces.Declare(strings.TrimSuffix(name, ":"), iri)
+
+	case "base":
+		iri, err := t.readIRI()
+		if err != nil {
+			return err
+		}
+
+		if t.base, err = url.Parse(iri); err != nil {
+			return t.errorf("bad base IRI '%s'", iri)
+		}
+
+	default:
+		return t.errorf("unknown directive '%s'", keyword)
+	}
+
+	if sparql {
+		return nil
+	}
+
+	return t.expect('.')
+}
+
+// readStatement reads a directive or the triples of a subject
+func (t *TurtleReader) readStatement() error {
+	if err := t.skipBlanks(); err != nil {
+		return err
+	}
+
+	t.start = t.pos
+	c, _ := t.peek()
+
+	if c == '@' {
+		t.read()
+		keyword, err := t.readWord()
+		if err != nil {
+			return err
+		}
+		return t.readDirective(keyword, false)
+	}
+
+	if c != '<' && c != '[' && c != '_' {
+		word, err := t.readWord()
+		if err != nil {
+			return err
+		}
+
+		if keyword := strings.ToLower(word); keyword == "prefix" || keyword == "base" {
+			return t.readDirective(keyword, true)
+		}
+
+		iri, err := t.namespaces.Expand(word)
+		if err != nil {
+			return t.errorf("%s", err)
+		}
+		if !strings.ContainsRune(word, ':') {
+			return t.errorf("unexpected '%s'", word)
+		}
+
+		if err := t.readPredicateObjectList("<" + iri + ">"); err != nil {
+			return err
+		}
+		return t.expect('.')
+	}
+
+	subject, err := t.readTerm()
+	if err != nil {
+		return err
+	}
+
+	// [ ... ] .
+	if err := t.skipBlanks(); err != nil && err != io.EOF {
+		return err
+	}
+	if c, _ := t.peek(); c == '.' && subject[0] == '_' {
+		t.read()
+		return nil
+	}
+
+	if err := t.readPredicateObjectList(subject); err != nil {
+		return err
+	}
+
+	return t.expect('.')
+}
+
+// NextTriple returns the next triple of the document, io.EOF at the end
+func (t *TurtleReader) NextTriple() (Triple, error) {
+	for len(t.pending) == 0 {
+		if err := t.readStatement(); err != nil {
+			return Triple{}, err
+		}
+	}
+
+	triple := t.pending[0]
+	t.pending = t.pending[1:]
+
+	return triple, nil
+}
+
+// declarationKinds maps the OWL types of entities to their declaration
+var declarationKinds = map[string]string{
+	"<" + OWLNamespace + "Class>":              "Class",
+	"<" + OWLNamespace + "ObjectProperty>":     "ObjectProperty",
+	"<" + OWLNamespace + "DatatypeProperty>":   "DataProperty",
+	"<" + OWLNamespace + "AnnotationProperty>": "AnnotationProperty",
+	"<" + OWLNamespace + "NamedIndividual>":    "NamedIndividual",
+	"<" + RDFSNamespace + "Datatype>":          "Datatype",
+}
+
+// Next returns the next triple as an assertion: rdf:type gives a
+// ClassAssertion (or a Declaration for the OWL entity types), other
+// predicates give an ObjectPropertyAssertion, or a DataPropertyAssertion
+// when the object is a literal
+func (t *TurtleReader) Next() (DLPredicate, error) {
+	triple, err := t.NextTriple()
+	if err != nil {
+		return DLPredicate{}, err
+	}
+
+	span := triple.Span
+	subject := newPredicate(triple.Subject, span)
+	object := newPredicate(triple.Object, span)
+
+	switch {
+	case triple.Predicate == "<"+RDFNamespace+"type>":
+		if kind, ok := declarationKinds[triple.Object]; ok {
+			return newPredicate("Declaration", span, newPredicate(kind, span, subject)), nil
+		}
+		return newPredicate("ClassAssertion", span, object, subject), nil
+
+	case IsLiteral(triple.Object):
+		return newPredicate("DataPropertyAssertion", span, newPredicate(triple.Predicate, span), subject, object), nil
+	}
+
+	return newPredicate("ObjectPropertyAssertion", span, newPredicate(triple.Predicate, span), subject, object), nil
+}
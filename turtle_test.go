@@ -0,0 +1,114 @@
+package godl
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTurtleReader(t *testing.T) {
+	s := `@prefix : <http://example.org/music#> .
+@base <http://example.org/data/> .
+PREFIX rdfs: <http://www.w3.org/2000/01/rdf-schema#>
+
+# instances
+:ravel a :artist, :human ;
+    :hasComposed <bolero> ;
+    rdfs:label "Maurice \"Ravel\""@fr ;
+    :age 62 .
+[] :hasComposed :pavane .
+_:x :hasArtist [ a :artist ] .
+`
+
+	reader := NewTurtleReader(strings.NewReader(s))
+	triples := make([]Triple, 0)
+
+	for {
+		triple, err := reader.NextTriple()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		triples = append(triples, triple)
+	}
+
+	expected := [][3]string{
+		{"<http://example.org/music#ravel>", "<" + RDFNamespace + "type>", "<http://example.org/music#artist>"},
+		{"<http://example.org/music#ravel>", "<" + RDFNamespace + "type>", "<http://example.org/music#human>"},
+		{"<http://example.org/music#ravel>", "<http://example.org/music#hasComposed>", "<http://example.org/data/bolero>"},
+		{"<http://example.org/music#ravel>", "<" + RDFSNamespace + "label>", `"Maurice \"Ravel\""@fr`},
+		{"<http://example.org/music#ravel>", "<http://example.org/music#age>", `"62"^^<` + XSDNamespace + `integer>`},
+		{"_:genid1", "<http://example.org/music#hasComposed>", "<http://example.org/music#pavane>"},
+		{"_:genid2", "<" + RDFNamespace + "type>", "<http://example.org/music#artist>"},
+		{"_:x", "<http://example.org/music#hasArtist>", "_:genid2"},
+	}
+
+	if len(triples) != len(expected) {
+		t.Fatalf("expected %d triples, got %d: %v", len(expected), len(triples), triples)
+	}
+
+	for i, triple := range triples {
+		if got := [3]string{triple.Subject, triple.Predicate, triple.Object}; got != expected[i] {
+			t.Errorf("triple %d: expected %v, got %v", i, expected[i], got)
+		}
+	}
+}
+
+func TestTurtleAssertions(t *testing.T) {
+	s := `<http://example.org/ravel> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/artist> .
+<http://example.org/ravel> <http://example.org/hasComposed> <http://example.org/bolero> .
+<http://example.org/hasComposed> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#ObjectProperty> .
+`
+
+	reader := NewNTriplesReader(strings.NewReader(s))
+	names := make([]string, 0)
+
+	for {
+		assertion, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		axiom, err := NewAxiom(&assertion, reader.Namespaces())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch a := axiom.(type) {
+		case ClassAssertion:
+			names = append(names, "ClassAssertion "+a.Class.String()+" "+a.Individual)
+		case ObjectPropertyAssertion:
+			names = append(names, "ObjectPropertyAssertion "+a.Property.String()+" "+a.Subject+" "+a.Object)
+		case Declaration:
+			names = append(names, "Declaration "+a.Kind+" "+a.Name)
+		}
+	}
+
+	expected := []string{
+		"ClassAssertion <http://example.org/artist> http://example.org/ravel",
+		"ObjectPropertyAssertion <http://example.org/hasComposed> http://example.org/ravel http://example.org/bolero",
+		"Declaration ObjectProperty http://example.org/hasComposed",
+	}
+
+	if strings.Join(names, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected assertions:\n%s", strings.Join(names, "\n"))
+	}
+}
+
+func TestTurtleErrors(t *testing.T) {
+	for _, s := range []string{
+		":a :b :c .",
+		"<a> <b> <c>",
+		"<a> <b> (<c>) .",
+		"<a> <b> \"unterminated .\n",
+	} {
+		reader := NewTurtleReader(strings.NewReader(s))
+		if _, err := reader.NextTriple(); err == nil || err == io.EOF {
+			t.Errorf("%q: expected an error, got %v", s, err)
+		}
+	}
+}
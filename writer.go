@@ -0,0 +1,253 @@
+package godl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// QuoteString returns s as a functional syntax string literal
+func QuoteString(s string) string {
+	var buffer bytes.Buffer
+
+	buffer.WriteByte('"')
+	for _, c := range s {
+		if c == '"' || c == '\\' {
+			buffer.WriteByte('\\')
+		}
+		buffer.WriteRune(c)
+	}
+	buffer.WriteByte('"')
+
+	return buffer.String()
+}
+
+// UnquoteString returns the value of the string literal s
+func UnquoteString(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s, fmt.Errorf("%s is not a quoted string", s)
+	}
+
+	var buffer bytes.Buffer
+	escaped := false
+
+	for _, c := range s[1 : len(s)-1] {
+		switch {
+		case escaped:
+			buffer.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			return s, fmt.Errorf("%s is not a quoted string", s)
+		default:
+			buffer.WriteRune(c)
+		}
+	}
+
+	if escaped {
+		return s, fmt.Errorf("%s is not a quoted string", s)
+	}
+
+	return buffer.String(), nil
+}
+
+// validName tells if name can be written as a single token
+func validName(name string) bool {
+	switch {
+	case name == "":
+		return false
+	case name[0] == '"':
+		quoted, suffix, ok := splitLiteral(name)
+		if _, err := UnquoteString(quoted); !ok || err != nil {
+			return false
+		}
+		return suffix == "" || validName(strings.TrimLeft(suffix, "^@"))
+	case name[0] == '<':
+		return len(name) > 1 && name[len(name)-1] == '>' &&
+			strings.IndexFunc(name[1:len(name)-1], func(c rune) bool { return c == '>' || unicode.IsSpace(c) }) < 0
+	case name[0] == '#':
+		return false
+	}
+
+	return strings.IndexFunc(name, isDelimiter) < 0
+}
+
+type functionalWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (fw *functionalWriter) write(s string) {
+	if fw.err == nil {
+		_, fw.err = fw.w.WriteString(s)
+	}
+}
+
+func (fw *functionalWriter) writeName(p *DLPredicate) {
+	if !validName(p.Name) && fw.err == nil {
+		fw.err = fmt.Errorf("%s: '%s' cannot be written in functional syntax", p.Span, p.Name)
+	}
+	fw.write(p.Name)
+}
+
+// writeInline writes p and its arguments on a single line
+func (fw *functionalWriter) writeInline(p *DLPredicate) {
+	// bare lists have no name
+	if p.Name != "" {
+		fw.writeName(p)
+
+		if len(p.Arguments) == 0 {
+			return
+		}
+	}
+
+	fw.write("(")
+	for i := range p.Arguments {
+		// Prefix(:=<...>)
+		if i > 0 && !strings.HasSuffix(p.Arguments[i-1].Name, "=") {
+			fw.write(" ")
+		}
+		fw.writeInline(&p.Arguments[i])
+	}
+	fw.write(")")
+}
+
+// writeOntology writes the axioms of the ontology p one per line
+func (fw *functionalWriter) writeOntology(p *DLPredicate) {
+	fw.writeName(p)
+	fw.write("(")
+
+	i := 0
+	for ; i < len(p.Arguments) && len(p.Arguments[i].Arguments) == 0; i++ {
+		if i > 0 {
+			fw.write(" ")
+		}
+		fw.writeInline(&p.Arguments[i])
+	}
+
+	for ; i < len(p.Arguments); i++ {
+		fw.write("\n   ")
+		fw.writeInline(&p.Arguments[i])
+	}
+
+	fw.write("\n)")
+}
+
+// WriteFunctional writes p in OWL functional syntax. The unnamed predicate
+// returned by Parse is written as a document, one top level predicate per
+// line, and the axioms of an Ontology are written one per line.
+func WriteFunctional(w io.Writer, p *DLPredicate) error {
+	fw := functionalWriter{w: bufio.NewWriter(w)}
+
+	if p.Name == "" {
+		for i := range p.Arguments {
+			if p.Arguments[i].Name == "Ontology" {
+				fw.writeOntology(&p.Arguments[i])
+			} else {
+				fw.writeInline(&p.Arguments[i])
+			}
+			fw.write("\n")
+		}
+	} else if p.Name == "Ontology" {
+		fw.writeOntology(p)
+	} else {
+		fw.writeInline(p)
+	}
+
+	if fw.err != nil {
+		return fw.err
+	}
+
+	return fw.w.Flush()
+}
+
+// FunctionalString returns p in OWL functional syntax, "" if it cannot be written
+func (p *DLPredicate) FunctionalString() string {
+	var buffer bytes.Buffer
+
+	if err := WriteFunctional(&buffer, p); err != nil {
+		return ""
+	}
+
+	return buffer.String()
+}
+
+// axiomRank orders the axioms of an ontology: IRIs and imports, then
+// annotations, then declarations, then the other axioms
+func axiomRank(p *DLPredicate) int {
+	switch {
+	case len(p.Arguments) == 0 || p.Name == "Import":
+		return 0
+	case p.Name == "Annotation":
+		return 1
+	case p.Name == "Declaration":
+		return 2
+	}
+
+	return 3
+}
+
+// inlineString returns p written on a single line, errors aside
+func (p *DLPredicate) inlineString() string {
+	var buffer bytes.Buffer
+
+	fw := functionalWriter{w: bufio.NewWriter(&buffer)}
+	fw.writeInline(p)
+	fw.w.Flush()
+
+	return buffer.String()
+}
+
+// sortArguments sorts the arguments of p by rank, then by their functional
+// syntax for the ranks listed in sorted
+func (p *DLPredicate) sortArguments(rank func(*DLPredicate) int, sorted func(int) bool) {
+	n := len(p.Arguments)
+	ranks := make([]int, n)
+	keys := make([]string, n)
+	order := make([]int, n)
+
+	for i := range p.Arguments {
+		ranks[i] = rank(&p.Arguments[i])
+		if sorted(ranks[i]) {
+			keys[i] = p.Arguments[i].inlineString()
+		}
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if ranks[a] != ranks[b] {
+			return ranks[a] < ranks[b]
+		}
+		return keys[a] < keys[b]
+	})
+
+	args := make([]DLPredicate, n)
+	for i, j := range order {
+		args[i] = p.Arguments[j]
+	}
+	p.Arguments = args
+}
+
+// SortAxioms sorts the Prefix declarations found among the arguments of p
+// and the axioms of its ontologies, so that equivalent documents are
+// written identically
+func (p *DLPredicate) SortAxioms() {
+	p.sortArguments(func(q *DLPredicate) int {
+		if q.Name == "Prefix" {
+			return 0
+		}
+		return 1
+	}, func(rank int) bool { return rank == 0 })
+
+	for i := range p.Arguments {
+		if p.Arguments[i].Name == "Ontology" {
+			p.Arguments[i].sortArguments(axiomRank, func(rank int) bool { return rank > 0 })
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package godl
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+// sameTree compares two predicates, ignoring their spans
+func sameTree(p *DLPredicate, q *DLPredicate) bool {
+	if p.Name != q.Name || len(p.Arguments) != len(q.Arguments) {
+		return false
+	}
+
+	for i := range p.Arguments {
+		if !sameTree(&p.Arguments[i], &q.Arguments[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestRoundTrip(t *testing.T) {
+	bs, err := ioutil.ReadFile("examples/et1/dlliteonto.owl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := `Prefix(:=<http://example.org/music#>)
+Prefix(xsd:=<http://www.w3.org/2001/XMLSchema#>)
+Ontology(<http://example.org/music>
+   AnnotationAssertion(rdfs:comment :painter ` + QuoteString(`a "good" \ painter`) + `)
+   SubClassOf(:painter ObjectSomeValuesFrom(:hasComposed owl:Thing))
+   HasKey(:painter (:hasComposed) ())
+)
+`
+
+	for _, input := range []string{string(bs), s} {
+		first, err := Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output := first.FunctionalString()
+		second, err := Parse(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !sameTree(&first, &second) {
+			t.Error("round trip failed:\n", output)
+		}
+	}
+
+	result, _ := Parse(s)
+	if output := result.FunctionalString(); output != s {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestSortAxioms(t *testing.T) {
+	p1, _ := Parse("Ontology(<o> SubClassOf(b c) Declaration(Class(b)) SubClassOf(a b))")
+	p2, _ := Parse("Ontology(<o> SubClassOf(a b) SubClassOf(b c) Declaration(Class(b)))")
+
+	p1.SortAxioms()
+	p2.SortAxioms()
+
+	if p1.FunctionalString() != p2.FunctionalString() {
+		t.Error("sorted ontologies differ:\n", p1.FunctionalString(), p2.FunctionalString())
+	}
+
+	expected := "Ontology(<o>\n   Declaration(Class(b))\n   SubClassOf(a b)\n   SubClassOf(b c)\n)\n"
+	if output := p1.FunctionalString(); output != expected {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	for _, s := range []string{"", "plain", `with "quotes"`, `back\slash`, "two  blanks (and parentheses)"} {
+		if value, err := UnquoteString(QuoteString(s)); err != nil || value != s {
+			t.Errorf("%q: got %q, %v", s, value, err)
+		}
+	}
+
+	p := DLPredicate{Name: "Comment", Arguments: []DLPredicate{{Name: "two words"}}}
+	if err := WriteFunctional(ioutil.Discard, &p); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+}